@@ -0,0 +1,182 @@
+package satellite
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ForemanUserHeader is the header used to impersonate a user when
+// authenticating through an OAuth consumer.
+const ForemanUserHeader = "FOREMAN-USER"
+
+// setAuthentication configures the client with one of the supported
+// authentication methods; in order of precedence:
+//  1. OAuth 1.0 consumer (oauth_consumer_key, oauth_consumer_secret and
+//     optionally oauth_user to impersonate a user),
+//  2. personal access token (username and token),
+//  3. password (username and password).
+//
+// Partially configured methods are rejected; if more than one method is
+// configured, the one with the highest precedence is used.
+func setAuthentication(client *resty.Client, config satelliteConfig, logger hclog.Logger) error {
+	methods, err := authenticationMethods(config)
+	if err != nil {
+		return err
+	}
+	if len(methods) > 1 {
+		logger.Warn("more than one authentication method configured, using the one with the highest precedence", "configured", methods, "used", methods[0])
+	}
+
+	switch methods[0] {
+	case authOAuth:
+		if isSet(config.OAuthUser) {
+			client.SetHeader(ForemanUserHeader, *config.OAuthUser)
+		}
+		key, secret := *config.OAuthConsumerKey, *config.OAuthConsumerSecret
+		client.SetPreRequestHook(func(_ *resty.Client, request *http.Request) error {
+			return signOAuth(request, key, secret)
+		})
+	case authToken:
+		// personal access tokens replace the password in basic authentication
+		client.SetBasicAuth(*config.Username, *config.Token)
+	case authPassword:
+		client.SetBasicAuth(*config.Username, *config.Password)
+	}
+	return nil
+}
+
+// The supported authentication methods, as reported in logs.
+const (
+	authOAuth    = "OAuth consumer"
+	authToken    = "personal access token"
+	authPassword = "password"
+)
+
+// authenticationMethods validates the credentials in the configuration and
+// returns the authentication methods they enable, in order of precedence.
+func authenticationMethods(config satelliteConfig) ([]string, error) {
+	hasKey, hasSecret, hasUser := isSet(config.OAuthConsumerKey), isSet(config.OAuthConsumerSecret), isSet(config.OAuthUser)
+	hasUsername, hasToken, hasPassword := isSet(config.Username), isSet(config.Token), isSet(config.Password)
+
+	if hasKey != hasSecret {
+		return nil, errors.New("invalid OAuth configuration: oauth_consumer_key and oauth_consumer_secret must be set together")
+	}
+	if hasUser && !hasKey {
+		return nil, errors.New("invalid OAuth configuration: oauth_user requires oauth_consumer_key and oauth_consumer_secret")
+	}
+	if (hasToken || hasPassword) && !hasUsername {
+		return nil, errors.New("invalid authentication configuration: token and password require username, the login of the Satellite user")
+	}
+
+	methods := []string{}
+	if hasKey {
+		methods = append(methods, authOAuth)
+	}
+	if hasToken {
+		methods = append(methods, authToken)
+	}
+	if hasPassword {
+		methods = append(methods, authPassword)
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("no authentication info available: set username and password, username and token (a personal access token), or oauth_consumer_key and oauth_consumer_secret")
+	}
+	return methods, nil
+}
+
+// isSet tells whether an optional string setting has a non-empty value.
+func isSet(value *string) bool {
+	return value != nil && *value != ""
+}
+
+// signOAuth signs the request as a two-legged OAuth 1.0 request with
+// HMAC-SHA1, as expected by Foreman for OAuth consumers.
+func signOAuth(request *http.Request, key string, secret string) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("error generating OAuth nonce: %w", err)
+	}
+
+	parameters := map[string]string{
+		"oauth_consumer_key":     key,
+		"oauth_nonce":            hex.EncodeToString(nonce),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(time.Now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+
+	values := request.URL.Query()
+	for k, v := range parameters {
+		values.Set(k, v)
+	}
+	parameters["oauth_signature"] = oauthSignature(request.Method, request.URL, values, secret, "")
+
+	keys := make([]string, 0, len(parameters))
+	for k := range parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	header := make([]string, 0, len(keys))
+	for _, k := range keys {
+		header = append(header, fmt.Sprintf(`%s="%s"`, k, oauthEscape(parameters[k])))
+	}
+	request.Header.Set("Authorization", "OAuth "+strings.Join(header, ", "))
+	return nil
+}
+
+// oauthSignature computes the HMAC-SHA1 signature of a request as per
+// RFC 5849, section 3.4; values must contain both the request parameters
+// and the oauth_* protocol parameters.
+func oauthSignature(method string, u *url.URL, values url.Values, consumerSecret string, tokenSecret string) string {
+	pairs := []string{}
+	for k, vs := range values {
+		for _, v := range vs {
+			pairs = append(pairs, oauthEscape(k)+"="+oauthEscape(v))
+		}
+	}
+	sort.Strings(pairs)
+
+	host := strings.ToLower(u.Host)
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme == "https" && strings.HasSuffix(host, ":443")) || (scheme == "http" && strings.HasSuffix(host, ":80")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	base := strings.Join([]string{
+		strings.ToUpper(method),
+		oauthEscape(scheme + "://" + host + u.EscapedPath()),
+		oauthEscape(strings.Join(pairs, "&")),
+	}, "&")
+
+	mac := hmac.New(sha1.New, []byte(oauthEscape(consumerSecret)+"&"+oauthEscape(tokenSecret)))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// oauthEscape percent-encodes a value as per RFC 5849, section 3.6: only
+// unreserved characters are left as they are.
+func oauthEscape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
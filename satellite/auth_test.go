@@ -0,0 +1,100 @@
+package satellite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-hclog"
+)
+
+func TestOAuthSignature(t *testing.T) {
+	// the reference example in Twitter's "Creating a signature" documentation
+	u, _ := url.Parse("https://api.twitter.com/1.1/statuses/update.json")
+	values := url.Values{
+		"include_entities":       {"true"},
+		"status":                 {"Hello Ladies + Gentlemen, a signed OAuth request!"},
+		"oauth_consumer_key":     {"xvz1evFS4wEEPTGEFPHBog"},
+		"oauth_nonce":            {"kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg"},
+		"oauth_signature_method": {"HMAC-SHA1"},
+		"oauth_timestamp":        {"1318622958"},
+		"oauth_token":            {"370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb"},
+		"oauth_version":          {"1.0"},
+	}
+	expected := "hCtSmYh+iHYCEqBWrE7C7hYmtUk="
+	actual := oauthSignature("post", u, values, "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw", "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE")
+	if actual != expected {
+		t.Fatalf("error: expected %q, got %q", expected, actual)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		config   satelliteConfig
+		valid    bool
+		expected func(http.Header) bool
+	}{
+		{
+			name:   "password",
+			config: satelliteConfig{Username: utils.PointerTo("admin"), Password: utils.PointerTo("secret")},
+			valid:  true,
+			expected: func(h http.Header) bool {
+				return h.Get("Authorization") == "Basic YWRtaW46c2VjcmV0"
+			},
+		},
+		{
+			name:   "token over password",
+			config: satelliteConfig{Username: utils.PointerTo("admin"), Password: utils.PointerTo("secret"), Token: utils.PointerTo("token")},
+			valid:  true,
+			expected: func(h http.Header) bool {
+				return h.Get("Authorization") == "Basic YWRtaW46dG9rZW4="
+			},
+		},
+		{
+			name:   "oauth over token",
+			config: satelliteConfig{Username: utils.PointerTo("admin"), Token: utils.PointerTo("token"), OAuthConsumerKey: utils.PointerTo("key"), OAuthConsumerSecret: utils.PointerTo("secret"), OAuthUser: utils.PointerTo("automation")},
+			valid:  true,
+			expected: func(h http.Header) bool {
+				return strings.HasPrefix(h.Get("Authorization"), `OAuth oauth_consumer_key="key", oauth_nonce=`) &&
+					strings.Contains(h.Get("Authorization"), `oauth_signature_method="HMAC-SHA1"`) &&
+					h.Get(ForemanUserHeader) == "automation"
+			},
+		},
+		{name: "nothing", config: satelliteConfig{}},
+		{name: "password without username", config: satelliteConfig{Password: utils.PointerTo("secret")}},
+		{name: "token without username", config: satelliteConfig{Token: utils.PointerTo("token")}},
+		{name: "key without secret", config: satelliteConfig{OAuthConsumerKey: utils.PointerTo("key")}},
+		{name: "user without consumer", config: satelliteConfig{Username: utils.PointerTo("admin"), Password: utils.PointerTo("secret"), OAuthUser: utils.PointerTo("automation")}},
+	}
+
+	for _, test := range tests {
+		client := resty.New().SetBaseURL(server.URL)
+		err := setAuthentication(client, test.config, hclog.NewNullLogger())
+		t.Logf("%s: %v", test.name, err)
+		if (err == nil) != test.valid {
+			t.Fatalf("error: %s: expected valid %t, got error %v", test.name, test.valid, err)
+		}
+		if err != nil {
+			continue
+		}
+		if _, err := client.R().SetQueryParam("search", "name ~ web*").Get("/api/hosts"); err != nil {
+			t.Fatal(err)
+		}
+		if !test.expected(headers) {
+			t.Fatalf("error: %s: unexpected headers %v", test.name, headers)
+		}
+	}
+}
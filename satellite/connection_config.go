@@ -1,23 +1,47 @@
 package satellite
 
 import (
+	"time"
+
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/schema"
 )
 
 type satelliteConfig struct {
-	Endpoint     *string `cty:"endpoint"`
-	Username     *string `cty:"username"`
-	Password     *string `cty:"password"`
-	Organisation *string `cty:"organisation"`
-	Location     *string `cty:"location"`
-	TraceLevel   *string `cty:"trace_level"`
+	Endpoint            *string  `cty:"endpoint"`
+	EndpointURL         *string  `cty:"endpoint_url"`
+	Username            *string  `cty:"username"`
+	Password            *string  `cty:"password"`
+	Organisation        *string  `cty:"organisation"`
+	Organizations       []string `cty:"organizations"`
+	Location            *string  `cty:"location"`
+	TraceLevel          *string  `cty:"trace_level"`
+	PerPage             *int     `cty:"per_page"`
+	MaxConcurrency      *int     `cty:"max_concurrency"`
+	HostIndexTTL        *string  `cty:"host_index_ttl"`
+	MaxRetries          *int     `cty:"max_retries"`
+	MinBackoff          *string  `cty:"min_backoff"`
+	MaxBackoff          *string  `cty:"max_backoff"`
+	CAFile              *string  `cty:"ca_file"`
+	CAPEM               *string  `cty:"ca_pem"`
+	ClientCertFile      *string  `cty:"client_cert_file"`
+	ClientKeyFile       *string  `cty:"client_key_file"`
+	InsecureSkipVerify  *bool    `cty:"insecure_skip_verify"`
+	Token               *string  `cty:"token"`
+	OAuthConsumerKey    *string  `cty:"oauth_consumer_key"`
+	OAuthConsumerSecret *string  `cty:"oauth_consumer_secret"`
+	OAuthUser           *string  `cty:"oauth_user"`
+	CredentialsSource   *string  `cty:"credentials_source"`
 }
 
 var ConfigSchema = map[string]*schema.Attribute{
 	"endpoint": {
 		Type: schema.TypeString,
 	},
+	// deprecated alias of endpoint
+	"endpoint_url": {
+		Type: schema.TypeString,
+	},
 	"username": {
 		Type: schema.TypeString,
 	},
@@ -27,12 +51,64 @@ var ConfigSchema = map[string]*schema.Attribute{
 	"organisation": {
 		Type: schema.TypeString,
 	},
+	"organizations": {
+		Type: schema.TypeList,
+		Elem: &schema.Attribute{Type: schema.TypeString},
+	},
 	"location": {
 		Type: schema.TypeString,
 	},
 	"trace_level": {
 		Type: schema.TypeString,
 	},
+	"per_page": {
+		Type: schema.TypeInt,
+	},
+	"max_concurrency": {
+		Type: schema.TypeInt,
+	},
+	"host_index_ttl": {
+		Type: schema.TypeString,
+	},
+	"max_retries": {
+		Type: schema.TypeInt,
+	},
+	"min_backoff": {
+		Type: schema.TypeString,
+	},
+	"max_backoff": {
+		Type: schema.TypeString,
+	},
+	"ca_file": {
+		Type: schema.TypeString,
+	},
+	"ca_pem": {
+		Type: schema.TypeString,
+	},
+	"client_cert_file": {
+		Type: schema.TypeString,
+	},
+	"client_key_file": {
+		Type: schema.TypeString,
+	},
+	"insecure_skip_verify": {
+		Type: schema.TypeBool,
+	},
+	"token": {
+		Type: schema.TypeString,
+	},
+	"oauth_consumer_key": {
+		Type: schema.TypeString,
+	},
+	"oauth_consumer_secret": {
+		Type: schema.TypeString,
+	},
+	"oauth_user": {
+		Type: schema.TypeString,
+	},
+	"credentials_source": {
+		Type: schema.TypeString,
+	},
 }
 
 func ConfigInstance() interface{} {
@@ -47,3 +123,35 @@ func GetConfig(connection *plugin.Connection) satelliteConfig {
 	config, _ := connection.Config.(satelliteConfig)
 	return config
 }
+
+// GetPerPage returns the configured page size for collection endpoints, or
+// the default one if none is set.
+func GetPerPage(connection *plugin.Connection) int {
+	config := GetConfig(connection)
+	if config.PerPage != nil && *config.PerPage > 0 {
+		return *config.PerPage
+	}
+	return DefaultPerPage
+}
+
+// GetMaxConcurrency returns the configured number of hosts that can be queried
+// in parallel, or the default one if none is set.
+func GetMaxConcurrency(connection *plugin.Connection) int {
+	config := GetConfig(connection)
+	if config.MaxConcurrency != nil && *config.MaxConcurrency > 0 {
+		return *config.MaxConcurrency
+	}
+	return DefaultMaxConcurrency
+}
+
+// GetHostIndexTTL returns the configured lifetime of the host index, or the
+// default one if none is set.
+func GetHostIndexTTL(connection *plugin.Connection) time.Duration {
+	config := GetConfig(connection)
+	if config.HostIndexTTL != nil {
+		if ttl, err := time.ParseDuration(*config.HostIndexTTL); err == nil && ttl > 0 {
+			return ttl
+		}
+	}
+	return DefaultHostIndexTTL
+}
@@ -2,7 +2,7 @@ package satellite
 
 import (
 	"context"
-	"errors"
+	"strconv"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
@@ -24,29 +24,59 @@ func getClient(ctx context.Context, d *plugin.QueryData) (*resty.Client, error)
 		SetHeader("Accept", "application/json,version=2").
 		SetHeader("Content-Type", "application/json")
 
-	satelliteConfig := GetConfig(d.Connection)
+	// the first connection is validated when it is loaded, see Plugin, but
+	// invalid configurations are only rejected here, when the client of the
+	// connection is first created
+	validated, err := getValidatedConfig(ctx, d.ConnectionCache, d.Connection)
+	if err != nil {
+		plugin.Logger(ctx).Error("invalid connection configuration", "error", err)
+		return nil, err
+	}
+	satelliteConfig := validated.Config
+
+	client.SetBaseURL(*satelliteConfig.Endpoint)
 
-	if satelliteConfig.Endpoint != nil {
-		client.SetBaseURL(*satelliteConfig.Endpoint)
-	} else {
-		plugin.Logger(ctx).Error("no API endpoint available")
-		return nil, errors.New("no API endpoint available")
+	if err := setAuthentication(client, satelliteConfig, plugin.Logger(ctx)); err != nil {
+		plugin.Logger(ctx).Error("invalid authentication configuration", "error", err)
+		return nil, err
 	}
 
-	if satelliteConfig.Username != nil && satelliteConfig.Password != nil {
-		client.SetBasicAuth(*satelliteConfig.Username, *satelliteConfig.Password)
-	} else {
-		plugin.Logger(ctx).Error("no authentication info available")
-		return nil, errors.New("no authentication info available")
+	tlsConfig := validated.TLS
+	if tlsConfig != nil {
+		if tlsConfig.InsecureSkipVerify {
+			plugin.Logger(ctx).Warn("server certificate verification is disabled")
+		}
+		client.SetTLSClientConfig(tlsConfig)
 	}
 
-	if satelliteConfig.Organisation != nil {
-		client.SetQueryParam("organization_id", *satelliteConfig.Organisation)
+	retries, err := getRetryConfig(satelliteConfig)
+	if err != nil {
+		plugin.Logger(ctx).Error("invalid retry configuration", "error", err)
+		return nil, err
 	}
+	setRetries(client, retries, plugin.Logger(ctx))
 
-	if satelliteConfig.Location != nil {
-		client.SetQueryParam("location_id", *satelliteConfig.Location)
+	// resolve the organisation and location before scoping the client to
+	// them, so that the lookup itself is not filtered
+	scope := map[string]string{}
+	for _, taxonomy := range []struct {
+		kind  taxonomy
+		value *string
+	}{
+		{organizationTaxonomy, satelliteConfig.Organisation},
+		{locationTaxonomy, satelliteConfig.Location},
+	} {
+		if taxonomy.value == nil {
+			continue
+		}
+		entity, err := getTaxonomy(ctx, d, client, taxonomy.kind, *taxonomy.value)
+		if err != nil {
+			plugin.Logger(ctx).Error("error resolving taxonomy", "entity", taxonomy.kind.Entity, "error", err)
+			return nil, err
+		}
+		scope[taxonomy.kind.Param] = strconv.Itoa(entity.ID)
 	}
+	client.SetQueryParams(scope)
 
 	// save to cache
 	plugin.Logger(ctx).Debug("saving satellite client to cache")
@@ -0,0 +1,191 @@
+package satellite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The sources the connection settings can be read from, in addition to the
+// attributes in the .spc file, which always take precedence.
+const (
+	// CredentialsSourceAuto falls back to the environment, then to hammer's
+	// configuration file; this is the default.
+	CredentialsSourceAuto = "auto"
+	// CredentialsSourceConfig only uses the attributes in the .spc file.
+	CredentialsSourceConfig = "config"
+	// CredentialsSourceEnv falls back to the SATELLITE_* environment variables.
+	CredentialsSourceEnv = "env"
+	// CredentialsSourceHammer falls back to hammer's foreman.yml file.
+	CredentialsSourceHammer = "hammer"
+)
+
+// HammerConfigFile is the path of hammer's Foreman module configuration,
+// relative to the user's home directory.
+var HammerConfigFile = filepath.Join(".hammer", "cli.modules.d", "foreman.yml")
+
+// resolveConfig fills the connection settings that are not set in the .spc
+// file from the source chosen via credentials_source. Credentials are only
+// completed with those of the same authentication method, see mergeConfig.
+func resolveConfig(config satelliteConfig) (satelliteConfig, error) {
+	if config.Endpoint == nil && config.EndpointURL != nil {
+		config.Endpoint = config.EndpointURL
+	}
+
+	source := CredentialsSourceAuto
+	if config.CredentialsSource != nil && *config.CredentialsSource != "" {
+		source = strings.ToLower(*config.CredentialsSource)
+	}
+
+	switch source {
+	case CredentialsSourceConfig:
+		return config, nil
+	case CredentialsSourceEnv:
+		return mergeConfig(config, environmentConfig()), nil
+	case CredentialsSourceHammer:
+		hammer, err := hammerConfig()
+		if err != nil {
+			return config, err
+		}
+		return mergeConfig(config, hammer), nil
+	case CredentialsSourceAuto:
+		config = mergeConfig(config, environmentConfig())
+		if isComplete(config) {
+			return config, nil
+		}
+		hammer, err := hammerConfig()
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return config, err
+		}
+		return mergeConfig(config, hammer), nil
+	}
+	return config, fmt.Errorf("invalid credentials_source %q: it must be one of %q, %q, %q or %q", source, CredentialsSourceAuto, CredentialsSourceConfig, CredentialsSourceEnv, CredentialsSourceHammer)
+}
+
+// environmentConfig reads the connection settings from the environment.
+func environmentConfig() satelliteConfig {
+	getenv := func(key string) *string {
+		if value := os.Getenv(key); value != "" {
+			return &value
+		}
+		return nil
+	}
+	return satelliteConfig{
+		Endpoint:            getenv("SATELLITE_ENDPOINT"),
+		Username:            getenv("SATELLITE_USERNAME"),
+		Password:            getenv("SATELLITE_PASSWORD"),
+		Token:               getenv("SATELLITE_TOKEN"),
+		OAuthConsumerKey:    getenv("SATELLITE_OAUTH_CONSUMER_KEY"),
+		OAuthConsumerSecret: getenv("SATELLITE_OAUTH_CONSUMER_SECRET"),
+		OAuthUser:           getenv("SATELLITE_OAUTH_USER"),
+		Organisation:        getenv("SATELLITE_ORGANIZATION"),
+		Location:            getenv("SATELLITE_LOCATION"),
+		CAFile:              getenv("SATELLITE_CA_FILE"),
+	}
+}
+
+// hammerConfig reads the server URL and credentials from hammer's Foreman
+// module configuration in the user's home directory.
+func hammerConfig() (satelliteConfig, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return satelliteConfig{}, fmt.Errorf("error locating hammer configuration: %w", err)
+	}
+	return readHammerConfig(filepath.Join(home, HammerConfigFile))
+}
+
+// readHammerConfig parses a hammer foreman.yml file, e.g.
+//
+//	:foreman:
+//	  :host: 'https://satellite.example.com'
+//	  :username: 'admin'
+//	  :password: 'changeme'
+func readHammerConfig(path string) (satelliteConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return satelliteConfig{}, fmt.Errorf("error reading hammer configuration %q: %w", path, err)
+	}
+
+	hammer := struct {
+		Foreman struct {
+			Host     string `yaml:":host"`
+			Username string `yaml:":username"`
+			Password string `yaml:":password"`
+		} `yaml:":foreman"`
+	}{}
+	if err := yaml.Unmarshal(data, &hammer); err != nil {
+		return satelliteConfig{}, fmt.Errorf("error parsing hammer configuration %q: %w", path, err)
+	}
+
+	optional := func(value string) *string {
+		if value != "" {
+			return &value
+		}
+		return nil
+	}
+	return satelliteConfig{
+		Endpoint: optional(hammer.Foreman.Host),
+		Username: optional(hammer.Foreman.Username),
+		Password: optional(hammer.Foreman.Password),
+	}, nil
+}
+
+// mergeConfig fills the settings that are not set in config with those in
+// fallback. Credentials are only completed within the authentication method
+// that config already uses, e.g. a username with the password or the token
+// in fallback; if config has no credentials at all, they are all taken from
+// fallback.
+func mergeConfig(config satelliteConfig, fallback satelliteConfig) satelliteConfig {
+	fill := func(value **string, other *string) {
+		if !isSet(*value) && other != nil {
+			*value = other
+		}
+	}
+
+	fill(&config.Endpoint, fallback.Endpoint)
+	if len(config.Organizations) == 0 {
+		fill(&config.Organisation, fallback.Organisation)
+	}
+	fill(&config.Location, fallback.Location)
+	fill(&config.CAFile, fallback.CAFile)
+
+	basic := isSet(config.Username) || isSet(config.Password) || isSet(config.Token)
+	oauth := isSet(config.OAuthConsumerKey) || isSet(config.OAuthConsumerSecret) || isSet(config.OAuthUser)
+	if !basic && !oauth {
+		config.Username = fallback.Username
+		config.Password = fallback.Password
+		config.Token = fallback.Token
+		config.OAuthConsumerKey = fallback.OAuthConsumerKey
+		config.OAuthConsumerSecret = fallback.OAuthConsumerSecret
+		config.OAuthUser = fallback.OAuthUser
+		return config
+	}
+	if basic {
+		fill(&config.Username, fallback.Username)
+		// the password and the token are alternatives
+		if !isSet(config.Password) && !isSet(config.Token) {
+			fill(&config.Password, fallback.Password)
+			fill(&config.Token, fallback.Token)
+		}
+	}
+	if oauth {
+		fill(&config.OAuthConsumerKey, fallback.OAuthConsumerKey)
+		fill(&config.OAuthConsumerSecret, fallback.OAuthConsumerSecret)
+		fill(&config.OAuthUser, fallback.OAuthUser)
+	}
+	return config
+}
+
+// isComplete tells whether config has an endpoint and usable credentials, so
+// that it needs no further fallback.
+func isComplete(config satelliteConfig) bool {
+	if !isSet(config.Endpoint) {
+		return false
+	}
+	methods, err := authenticationMethods(config)
+	return err == nil && len(methods) > 0
+}
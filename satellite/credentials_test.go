@@ -0,0 +1,121 @@
+package satellite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+)
+
+func TestReadHammerConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "foreman.yml")
+	data := `:foreman:
+  :host: 'https://satellite.example.com'
+  :username: 'admin'
+  :password: 'changeme'
+`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := readHammerConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint == nil || *config.Endpoint != "https://satellite.example.com" {
+		t.Fatalf("error: unexpected endpoint %v", config.Endpoint)
+	}
+	if config.Username == nil || *config.Username != "admin" || config.Password == nil || *config.Password != "changeme" {
+		t.Fatalf("error: unexpected credentials %v/%v", config.Username, config.Password)
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	// make sure no hammer configuration is found
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SATELLITE_ENDPOINT", "https://env.example.com")
+	t.Setenv("SATELLITE_USERNAME", "env-user")
+	t.Setenv("SATELLITE_PASSWORD", "env-password")
+	t.Setenv("SATELLITE_ORGANIZATION", "ACME")
+
+	// environment fallback
+	config, err := resolveConfig(satelliteConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *config.Endpoint != "https://env.example.com" || *config.Username != "env-user" || *config.Password != "env-password" || *config.Organisation != "ACME" {
+		t.Fatalf("error: unexpected configuration %s", utils.ToJSON(config))
+	}
+
+	// .spc attributes take precedence and credentials are not mixed
+	config, err = resolveConfig(satelliteConfig{
+		Endpoint: utils.PointerTo("https://spc.example.com"),
+		Username: utils.PointerTo("spc-user"),
+		Token:    utils.PointerTo("spc-token"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *config.Endpoint != "https://spc.example.com" || *config.Username != "spc-user" || config.Password != nil || *config.Organisation != "ACME" {
+		t.Fatalf("error: unexpected configuration %s", utils.ToJSON(config))
+	}
+
+	// a username in the .spc is completed with the password in the environment
+	config, err = resolveConfig(satelliteConfig{Username: utils.PointerTo("spc-user")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *config.Username != "spc-user" || config.Password == nil || *config.Password != "env-password" {
+		t.Fatalf("error: unexpected configuration %s", utils.ToJSON(config))
+	}
+
+	// config only
+	config, err = resolveConfig(satelliteConfig{CredentialsSource: utils.PointerTo(CredentialsSourceConfig)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Endpoint != nil || config.Username != nil {
+		t.Fatalf("error: unexpected configuration %s", utils.ToJSON(config))
+	}
+
+	// hammer is required but missing
+	if _, err = resolveConfig(satelliteConfig{CredentialsSource: utils.PointerTo(CredentialsSourceHammer)}); err == nil {
+		t.Fatal("error: expected missing hammer configuration")
+	}
+
+	// invalid source
+	if _, err = resolveConfig(satelliteConfig{CredentialsSource: utils.PointerTo("vault")}); err == nil {
+		t.Fatal("error: expected invalid credentials source")
+	}
+}
+
+func TestResolveConfigMalformedHammer(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, key := range []string{"SATELLITE_ENDPOINT", "SATELLITE_USERNAME", "SATELLITE_PASSWORD", "SATELLITE_TOKEN"} {
+		t.Setenv(key, "")
+	}
+	path := filepath.Join(home, HammerConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(":foreman: [unterminated"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the hammer configuration is not needed
+	config, err := resolveConfig(satelliteConfig{
+		Endpoint: utils.PointerTo("https://spc.example.com"),
+		Username: utils.PointerTo("spc-user"),
+		Password: utils.PointerTo("spc-password"),
+	})
+	if err != nil || *config.Username != "spc-user" {
+		t.Fatalf("error: unexpected error %v", err)
+	}
+
+	// the hammer configuration is needed
+	if _, err := resolveConfig(satelliteConfig{Endpoint: utils.PointerTo("https://spc.example.com")}); err == nil {
+		t.Fatal("error: expected malformed hammer configuration")
+	}
+}
@@ -0,0 +1,151 @@
+package satellite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// DefaultMaxConcurrency is the number of hosts queried in parallel when
+// max_concurrency is not configured.
+const DefaultMaxConcurrency = 5
+
+// fanOut runs fetch against every host produced by list, using a bounded pool
+// of workers; the rows of each host are streamed as soon as the host has been
+// completely retrieved. A failure on one host is logged and does not affect
+// the others: an error is returned only if the hosts cannot be listed or if
+// no host at all could be queried or, if strict is set, if any host failed
+// other than because it does not exist; in that case the rows of the other
+// hosts may already have been streamed.
+func fanOut[T any](ctx context.Context, d *plugin.QueryData, strict bool, list func(stream func(apiHost) bool) error, fetch func(ctx context.Context, host apiHost, stream func(T) bool) error) error {
+	concurrency := GetMaxConcurrency(d.Connection)
+
+	// the workers' context is cancelled as soon as the query has enough rows
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		hosts     = make(chan apiHost)
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+		succeeded int
+		failures  []string
+		notFound  int
+	)
+
+	plugin.Logger(ctx).Debug("fanning out host queries", "concurrency", concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range hosts {
+				rows := []T{}
+				err := fetch(workerCtx, host, func(row T) bool {
+					rows = append(rows, row)
+					return true
+				})
+
+				// streaming is serialised, the query data is not goroutine-safe
+				lock.Lock()
+				if err != nil {
+					if workerCtx.Err() == nil {
+						plugin.Logger(ctx).Warn("error querying host, skipping", "id", host.ID, "name", host.Name, "error", err)
+						failures = append(failures, fmt.Sprintf("host %d (%s): %v", host.ID, host.Name, err))
+						if errors.Is(err, ErrNotFound) {
+							notFound++
+						}
+					}
+				} else {
+					succeeded++
+					for _, row := range rows {
+						if workerCtx.Err() != nil {
+							break
+						}
+						d.StreamListItem(ctx, row)
+						if d.RowsRemaining(ctx) == 0 {
+							plugin.Logger(ctx).Debug("no more rows required, cancelling host queries")
+							cancel()
+						}
+					}
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	err := list(func(host apiHost) bool {
+		select {
+		case hosts <- host:
+			return true
+		case <-workerCtx.Done():
+			return false
+		}
+	})
+	close(hosts)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	if succeeded == 0 && len(failures) > 0 && notFound == len(failures) {
+		return fmt.Errorf("error querying all %d hosts: %s: %w", len(failures), strings.Join(failures, "; "), ErrNotFound)
+	}
+	if succeeded == 0 && len(failures) > 0 {
+		return fmt.Errorf("error querying all %d hosts: %s", len(failures), strings.Join(failures, "; "))
+	}
+	if strict && len(failures) > notFound {
+		return fmt.Errorf("error querying %d of the selected hosts: %s", len(failures), strings.Join(failures, "; "))
+	}
+	if len(failures) > 0 {
+		plugin.Logger(ctx).Warn("some hosts could not be queried", "failed", len(failures), "succeeded", succeeded, "failures", failures)
+	}
+	return nil
+}
+
+// fanOutHosts runs fetch against the hosts selected by the host_id and
+// host_name quals or, if there are none, against all the hosts in scope; the
+// query fails if any of the hosts named in the quals cannot be queried, while
+// failures on the hosts of a fleet-wide query are only logged.
+func fanOutHosts[T any](ctx context.Context, d *plugin.QueryData, client *resty.Client, fetch func(ctx context.Context, host apiHost, stream func(T) bool) error) error {
+	hosts, selected, err := qualHosts(ctx, d, client)
+	if err != nil {
+		return err
+	}
+	if !selected {
+		return fanOut(ctx, d, false, func(stream func(apiHost) bool) error {
+			return listSatelliteHostImpl(ctx, d, client, true, "", stream)
+		}, fetch)
+	}
+
+	plugin.Logger(ctx).Debug("running query against selected hosts", "hosts", len(hosts))
+	return fanOut(ctx, d, true, func(stream func(apiHost) bool) error {
+		for _, host := range hosts {
+			if !stream(host) {
+				break
+			}
+		}
+		return nil
+	}, func(ctx context.Context, host apiHost, stream func(T) bool) error {
+		if host.OrganizationID == 0 && needsOrganization(d) {
+			found, ok, err := lookupHost(ctx, d, client, host.ID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				plugin.Logger(ctx).Debug("host not found in the organizations in scope", "id", host.ID)
+				return nil
+			}
+			if host.Name != "" {
+				// keep the name in the quals, see qualHosts
+				found.Name = host.Name
+			}
+			host = found
+		}
+		return fetch(ctx, host, stream)
+	})
+}
@@ -0,0 +1,54 @@
+package satellite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestFanOutFailures(t *testing.T) {
+	list := func(ids ...int) func(stream func(apiHost) bool) error {
+		return func(stream func(apiHost) bool) error {
+			for _, id := range ids {
+				if !stream(apiHost{ID: id, Name: fmt.Sprintf("web%02d.example.com", id)}) {
+					break
+				}
+			}
+			return nil
+		}
+	}
+	// host 1 has no rows, host 2 fails and host 3 does not exist
+	fetch := func(ctx context.Context, host apiHost, stream func(struct{}) bool) error {
+		switch host.ID {
+		case 2:
+			return errors.New("internal server error")
+		case 3:
+			return fmt.Errorf("host %d: %w", host.ID, ErrNotFound)
+		}
+		return nil
+	}
+
+	tests := []struct {
+		strict   bool
+		hosts    []int
+		expected string
+	}{
+		{strict: false, hosts: []int{1, 2, 3}},
+		{strict: true, hosts: []int{1, 3}},
+		{strict: true, hosts: []int{1, 2, 3}, expected: "host 2 (web02.example.com): internal server error"},
+		{strict: false, hosts: []int{2}, expected: "error querying all 1 hosts"},
+	}
+	for _, test := range tests {
+		err := fanOut(testContext(), &plugin.QueryData{}, test.strict, list(test.hosts...), fetch)
+		if test.expected == "" && err != nil {
+			t.Fatalf("error: hosts %v (strict %t): unexpected error %v", test.hosts, test.strict, err)
+		}
+		if test.expected != "" && (err == nil || !strings.Contains(err.Error(), test.expected)) {
+			t.Fatalf("error: hosts %v (strict %t): expected error %q, got %v", test.hosts, test.strict, test.expected, err)
+		}
+	}
+}
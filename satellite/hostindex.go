@@ -0,0 +1,251 @@
+package satellite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// DefaultHostIndexTTL is how long the host index is cached when
+// host_index_ttl is not configured.
+const DefaultHostIndexTTL = 5 * time.Minute
+
+const SatelliteHostIndexKey = "satellite_host_index"
+
+// ErrNotFound is returned when the requested hosts do not exist, either
+// because the API answered 404 or because none of the requested host names
+// is known; the tables ignore it, so that such queries return no rows.
+var ErrNotFound = errors.New("not found")
+
+// isNotFoundError tells whether err is, or wraps, ErrNotFound.
+func isNotFoundError(_ context.Context, _ *plugin.QueryData, _ *plugin.HydrateData, err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// hostIndex maps the identities of the hosts in scope onto the hosts; each
+// host is indexed by its lower-case name (i.e. its FQDN), by its short name,
+// if that is not ambiguous, and by its certname, if the listing reports it.
+type hostIndex struct {
+	ByName map[string]apiHost
+	ByID   map[int]apiHost
+	// CertNames is set if the index was built from a full listing, which
+	// reports the certnames of the hosts too.
+	CertNames bool
+}
+
+// newHostIndex indexes the given hosts.
+func newHostIndex(hosts []apiHost) hostIndex {
+	index := hostIndex{
+		ByName: map[string]apiHost{},
+		ByID:   map[int]apiHost{},
+	}
+	short := map[string][]apiHost{}
+	for _, host := range hosts {
+		index.ByID[host.ID] = host
+		name := strings.ToLower(host.Name)
+		index.ByName[name] = host
+		if certname := strings.ToLower(host.CertName); certname != "" {
+			index.ByName[certname] = host
+		}
+		if i := strings.Index(name, "."); i > 0 {
+			short[name[:i]] = append(short[name[:i]], host)
+		}
+	}
+	for name, hosts := range short {
+		if _, ok := index.ByName[name]; !ok && len(hosts) == 1 {
+			index.ByName[name] = hosts[0]
+		}
+	}
+	return index
+}
+
+// lookup finds a host by name, FQDN or certname, ignoring case.
+func (i hostIndex) lookup(name string) (apiHost, bool) {
+	host, ok := i.ByName[strings.ToLower(name)]
+	return host, ok
+}
+
+// getHostIndex returns the index of the hosts in scope, building it from a
+// thin listing the first time and then caching it in the connection cache
+// for host_index_ttl; a thin listing only reports the IDs and names of the
+// hosts, so their certnames are only indexed when a lookup misses, see
+// getHostIndexWithCertNames.
+func getHostIndex(ctx context.Context, d *plugin.QueryData, client *resty.Client) (hostIndex, error) {
+	if cachedData, ok := d.ConnectionManager.Cache.Get(SatelliteHostIndexKey); ok {
+		plugin.Logger(ctx).Debug("returning host index from cache")
+		return cachedData.(hostIndex), nil
+	}
+	return cacheHostIndex(ctx, d, client, true)
+}
+
+// getHostIndexWithCertNames returns the index of the hosts in scope including
+// their certnames, rebuilding and caching it from a full listing if the
+// cached one was built from a thin listing.
+func getHostIndexWithCertNames(ctx context.Context, d *plugin.QueryData, client *resty.Client) (hostIndex, error) {
+	if cachedData, ok := d.ConnectionManager.Cache.Get(SatelliteHostIndexKey); ok && cachedData.(hostIndex).CertNames {
+		plugin.Logger(ctx).Debug("returning host index with certnames from cache")
+		return cachedData.(hostIndex), nil
+	}
+	return cacheHostIndex(ctx, d, client, false)
+}
+
+// cacheHostIndex builds the index of the hosts in scope and caches it for
+// host_index_ttl.
+func cacheHostIndex(ctx context.Context, d *plugin.QueryData, client *resty.Client, thin bool) (hostIndex, error) {
+	// the index must not depend on the quals of the query it is built for
+	organizations, scoped, err := configuredOrganizations(ctx, d, client)
+	if err != nil {
+		return hostIndex{}, err
+	}
+	index, err := buildHostIndex(ctx, d, client, organizations, scoped, thin)
+	if err != nil {
+		plugin.Logger(ctx).Error("error building host index", "error", err)
+		return hostIndex{}, err
+	}
+	if ctx.Err() != nil {
+		// a partial index must not be cached
+		return hostIndex{}, ctx.Err()
+	}
+
+	ttl := GetHostIndexTTL(d.Connection)
+	plugin.Logger(ctx).Debug("saving host index to cache", "hosts", len(index.ByID), "certnames", index.CertNames, "ttl", ttl)
+	d.ConnectionManager.Cache.SetWithTTL(SatelliteHostIndexKey, index, ttl)
+	return index, nil
+}
+
+// buildHostIndex lists the hosts in the given organizations, or all the
+// visible ones if the listing is not scoped, and indexes them. Thin listings
+// do not report the organization of the hosts, so if the listing is not
+// scoped they are listed one visible organization at a time.
+func buildHostIndex(ctx context.Context, d *plugin.QueryData, client *resty.Client, organizations []apiTaxonomy, scoped bool, thin bool) (hostIndex, error) {
+	if thin && !scoped {
+		var err error
+		organizations, err = listTaxonomies(ctx, client, GetPerPage(d.Connection), organizationTaxonomy)
+		if err != nil {
+			plugin.Logger(ctx).Error("error listing organizations", "error", err)
+			return hostIndex{}, err
+		}
+		scoped = true
+	}
+	hosts := []apiHost{}
+	err := listHosts(ctx, d, client, organizations, scoped, thin, "", func(host apiHost) bool {
+		hosts = append(hosts, host)
+		return true
+	})
+	if err != nil {
+		return hostIndex{}, err
+	}
+	index := newHostIndex(hosts)
+	index.CertNames = !thin
+	return index, nil
+}
+
+// lookupHostName finds a host by name, FQDN or certname in the index of the
+// hosts in scope; the certnames are indexed only if the name is not found
+// otherwise.
+func lookupHostName(ctx context.Context, d *plugin.QueryData, client *resty.Client, name string) (apiHost, bool, error) {
+	index, err := getHostIndex(ctx, d, client)
+	if err != nil {
+		return apiHost{}, false, err
+	}
+	if host, ok := index.lookup(name); ok || index.CertNames {
+		return host, ok, nil
+	}
+	if index, err = getHostIndexWithCertNames(ctx, d, client); err != nil {
+		return apiHost{}, false, err
+	}
+	host, ok := index.lookup(name)
+	return host, ok, nil
+}
+
+// qualHosts returns the hosts selected by the host_id or host_name quals,
+// including IN (...) lists; selected is false if there are no such quals.
+// Unknown host names are skipped, and ErrNotFound is returned if none of
+// them is known.
+func qualHosts(ctx context.Context, d *plugin.QueryData, client *resty.Client) (hosts []apiHost, selected bool, err error) {
+	ids, hasIDs := d.EqualsQuals["host_id"]
+	names, hasNames := d.EqualsQuals["host_name"]
+	if !hasIDs && !hasNames {
+		return nil, false, nil
+	}
+
+	index, err := getHostIndex(ctx, d, client)
+	if err != nil {
+		return nil, true, err
+	}
+
+	hosts, unknown := index.selectHosts(ids, names)
+	if len(unknown) > 0 && !index.CertNames {
+		// the unknown names may be certnames
+		if index, err = getHostIndexWithCertNames(ctx, d, client); err != nil {
+			return nil, true, err
+		}
+		hosts, unknown = index.selectHosts(ids, names)
+	}
+	if len(unknown) > 0 {
+		plugin.Logger(ctx).Warn("unknown host names, skipping", "names", unknown)
+	}
+	if len(hosts) == 0 {
+		return nil, true, fmt.Errorf("unknown host names %s: %w", strings.Join(unknown, ", "), ErrNotFound)
+	}
+	return hosts, true, nil
+}
+
+// selectHosts returns the hosts with the given IDs or, if there are none, the
+// hosts with the given names, along with the names that are unknown. Since
+// names are matched regardless of case and by short name too, the hosts carry
+// the names in the quals rather than their own, or Postgres would discard
+// their rows when it checks them against the quals; a host whose names or ID
+// appear more than once in the quals is returned only once, under the first
+// of its names.
+func (i hostIndex) selectHosts(ids *proto.QualValue, names *proto.QualValue) (hosts []apiHost, unknown []string) {
+	selected := map[int]bool{}
+	named := func(host apiHost) apiHost {
+		if names == nil {
+			return host
+		}
+		for _, value := range qualValues(names) {
+			if found, ok := i.lookup(value.GetStringValue()); ok && found.ID == host.ID {
+				host.Name = value.GetStringValue()
+				break
+			}
+		}
+		return host
+	}
+
+	if ids != nil {
+		for _, value := range qualValues(ids) {
+			id := int(value.GetInt64Value())
+			host, ok := i.ByID[id]
+			if !ok {
+				// the host may have been created after the index was built
+				host = apiHost{ID: id}
+			}
+			if !selected[id] {
+				selected[id] = true
+				hosts = append(hosts, named(host))
+			}
+		}
+		return hosts, nil
+	}
+
+	for _, value := range qualValues(names) {
+		name := value.GetStringValue()
+		if host, ok := i.lookup(name); ok {
+			if !selected[host.ID] {
+				selected[host.ID] = true
+				host.Name = name
+				hosts = append(hosts, host)
+			}
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	return hosts, unknown
+}
@@ -0,0 +1,155 @@
+package satellite
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestHostIndex(t *testing.T) {
+	index := newHostIndex([]apiHost{
+		{ID: 1, Name: "web01.example.com"},
+		{ID: 2, Name: "db01.example.com", CertName: "db01.prod.example.com"},
+		{ID: 3, Name: "app01.dev.example.com"},
+		{ID: 4, Name: "app01.prod.example.com"},
+		{ID: 5, Name: "mail"},
+	})
+
+	tests := []struct {
+		name     string
+		expected int
+	}{
+		{name: "web01.example.com", expected: 1},
+		{name: "WEB01.Example.COM", expected: 1},
+		{name: "web01", expected: 1},
+		{name: "db01.prod.example.com", expected: 2},
+		{name: "db01", expected: 2},
+		{name: "app01.prod.example.com", expected: 4},
+		{name: "app01"},
+		{name: "mail", expected: 5},
+		{name: "unknown.example.com"},
+	}
+	for _, test := range tests {
+		host, ok := index.lookup(test.name)
+		if ok != (test.expected != 0) || host.ID != test.expected {
+			t.Fatalf("error: %q: expected host %d, got %d (found %t)", test.name, test.expected, host.ID, ok)
+		}
+	}
+
+	if index.ByID[3].Name != "app01.dev.example.com" {
+		t.Fatalf("error: unexpected host by ID %v", index.ByID[3])
+	}
+}
+
+func TestSelectHosts(t *testing.T) {
+	index := newHostIndex([]apiHost{
+		{ID: 1, Name: "web01.example.com"},
+		{ID: 2, Name: "db01.example.com"},
+	})
+
+	// the hosts carry the names in the quals, which Postgres checks the rows
+	// against
+	hosts, unknown := index.selectHosts(nil, listQual("WEB01.example.com", "db01", "mail"))
+	if len(hosts) != 2 || hosts[0].Name != "WEB01.example.com" || hosts[1].Name != "db01" || hosts[1].ID != 2 {
+		t.Fatalf("error: unexpected hosts %v", hosts)
+	}
+	if len(unknown) != 1 || unknown[0] != "mail" {
+		t.Fatalf("error: unexpected unknown names %v", unknown)
+	}
+
+	hosts, _ = index.selectHosts(intQual(1), stringQual("web01"))
+	if len(hosts) != 1 || hosts[0].ID != 1 || hosts[0].Name != "web01" {
+		t.Fatalf("error: unexpected hosts %v", hosts)
+	}
+
+	hosts, _ = index.selectHosts(intQual(3), nil)
+	if len(hosts) != 1 || hosts[0].ID != 3 || hosts[0].Name != "" {
+		t.Fatalf("error: unexpected hosts %v", hosts)
+	}
+
+	// aliases of the same host select it only once, or its rows would be
+	// returned once per alias
+	hosts, unknown = index.selectHosts(nil, listQual("web01", "WEB01.example.com", "web01.example.com"))
+	if len(hosts) != 1 || hosts[0].ID != 1 || hosts[0].Name != "web01" || len(unknown) != 0 {
+		t.Fatalf("error: unexpected hosts %v (unknown %v)", hosts, unknown)
+	}
+
+	ids := &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{
+		Values: []*proto.QualValue{intQual(2), intQual(2)},
+	}}}
+	hosts, _ = index.selectHosts(ids, nil)
+	if len(hosts) != 1 || hosts[0].ID != 2 {
+		t.Fatalf("error: unexpected hosts %v", hosts)
+	}
+}
+
+func TestBuildHostIndex(t *testing.T) {
+	thin := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/organizations":
+			fmt.Fprint(w, `{"total":1,"subtotal":1,"page":1,"per_page":20,"results":[{"id": 1, "name": "ACME"}]}`)
+		case r.URL.Path == "/api/hosts" && r.URL.Query().Get("thin") == "true":
+			// thin listings only report the IDs and names of the hosts
+			thin++
+			if r.URL.Query().Get("organization_id") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"total":1,"subtotal":1,"page":1,"per_page":20,"results":[{"id": 2, "name": "db01.example.com"}]}`)
+		case r.URL.Path == "/api/hosts":
+			fmt.Fprint(w, `{"total":1,"subtotal":1,"page":1,"per_page":20,"results":[
+				{"id": 2, "name": "db01.example.com", "certname": "db01.prod.example.com", "organization_id": 1, "organization_name": "ACME"}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	index, err := buildHostIndex(testContext(), &plugin.QueryData{}, client, nil, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thin != 1 || index.CertNames {
+		t.Fatalf("error: expected an index from 1 thin listing, got %d (certnames %t)", thin, index.CertNames)
+	}
+	host, ok := index.lookup("DB01.example.com")
+	if !ok || host.ID != 2 || host.OrganizationID != 1 || host.OrganizationName != "ACME" {
+		t.Fatalf("error: unexpected host %v (found %t)", host, ok)
+	}
+	if _, ok := index.lookup("db01.prod.example.com"); ok {
+		t.Fatal("error: unexpected certname in an index from a thin listing")
+	}
+
+	index, err = buildHostIndex(testContext(), &plugin.QueryData{}, client, nil, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, ok = index.lookup("DB01.prod.example.com")
+	if !ok || !index.CertNames || host.ID != 2 || host.OrganizationName != "ACME" {
+		t.Fatalf("error: unexpected host %v (found %t)", host, ok)
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	server := newPagedServer(1)
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+	err := paginate(testContext(), client, 0, "/api/missing", nil, func(struct{}) bool { return true })
+	if !isNotFoundError(testContext(), nil, nil, err) {
+		t.Fatalf("error: expected not found error, got %v", err)
+	}
+	if isNotFoundError(testContext(), nil, nil, fmt.Errorf("error querying hosts")) {
+		t.Fatal("error: unexpected not found error")
+	}
+}
@@ -0,0 +1,187 @@
+package satellite
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// AllOrganizations, when listed in organizations, stands for all the
+// organizations visible to the API user.
+const AllOrganizations = "*"
+
+const SatelliteOrganizationsKey = "satellite_organizations"
+
+// organizationKeyColumns are the optional key columns that restrict a listing
+// to some of the organizations in scope.
+func organizationKeyColumns() plugin.KeyColumnSlice {
+	return plugin.KeyColumnSlice{
+		&plugin.KeyColumn{
+			Name:    "organization_id",
+			Require: plugin.Optional,
+		},
+		&plugin.KeyColumn{
+			Name:    "organization_name",
+			Require: plugin.Optional,
+		},
+	}
+}
+
+// scopedOrganizations is what is cached of the organizations the connection
+// is scoped to.
+type scopedOrganizations struct {
+	Organizations []apiTaxonomy
+	Scoped        bool
+}
+
+// getOrganizations returns the organizations that listings must be run
+// against, one at a time: the one in organisation, or those in organizations;
+// if neither is set, scoped is false and listings must be run once, without
+// any organization. The organization_id and organization_name quals, if any,
+// restrict the organizations further.
+func getOrganizations(ctx context.Context, d *plugin.QueryData, client *resty.Client) ([]apiTaxonomy, bool, error) {
+	organizations, scoped, err := configuredOrganizations(ctx, d, client)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids, names := organizationQuals(d)
+	if ids == nil && names == nil {
+		return organizations, scoped, nil
+	}
+	if !scoped {
+		if organizations, err = listTaxonomies(ctx, client, GetPerPage(d.Connection), organizationTaxonomy); err != nil {
+			plugin.Logger(ctx).Error("error listing organizations", "error", err)
+			return nil, false, err
+		}
+	}
+	organizations = filterOrganizations(organizations, ids, names)
+	plugin.Logger(ctx).Debug("organizations restricted by quals", "organizations", len(organizations))
+	return organizations, true, nil
+}
+
+// configuredOrganizations resolves the organizations in the connection
+// configuration the first time, and then caches them in the connection cache.
+func configuredOrganizations(ctx context.Context, d *plugin.QueryData, client *resty.Client) ([]apiTaxonomy, bool, error) {
+	if cachedData, ok := d.ConnectionManager.Cache.Get(SatelliteOrganizationsKey); ok {
+		plugin.Logger(ctx).Debug("returning organizations from cache")
+		scope := cachedData.(scopedOrganizations)
+		return scope.Organizations, scope.Scoped, nil
+	}
+
+	validated, err := getValidatedConfig(ctx, d.ConnectionCache, d.Connection)
+	if err != nil {
+		return nil, false, err
+	}
+	config := validated.Config
+
+	scope := scopedOrganizations{}
+	if config.Organisation != nil {
+		organization, err := getTaxonomy(ctx, d, client, organizationTaxonomy, *config.Organisation)
+		if err != nil {
+			return nil, false, err
+		}
+		scope = scopedOrganizations{Organizations: []apiTaxonomy{organization}, Scoped: true}
+	} else if len(config.Organizations) > 0 {
+		entities, err := listTaxonomies(ctx, client, GetPerPage(d.Connection), organizationTaxonomy)
+		if err != nil {
+			plugin.Logger(ctx).Error("error listing organizations", "error", err)
+			return nil, false, err
+		}
+		organizations, err := selectOrganizations(entities, config.Organizations)
+		if err != nil {
+			return nil, false, err
+		}
+		scope = scopedOrganizations{Organizations: organizations, Scoped: true}
+	}
+
+	plugin.Logger(ctx).Debug("saving organizations to cache", "scoped", scope.Scoped, "organizations", len(scope.Organizations))
+	d.ConnectionManager.Cache.Set(SatelliteOrganizationsKey, scope)
+	return scope.Organizations, scope.Scoped, nil
+}
+
+// selectOrganizations returns the organizations identified by values, in
+// order and without duplicates; AllOrganizations selects all of them.
+func selectOrganizations(entities []apiTaxonomy, values []string) ([]apiTaxonomy, error) {
+	selected := []apiTaxonomy{}
+	seen := map[int]bool{}
+	for _, value := range values {
+		matches := entities
+		if value != AllOrganizations {
+			entity, ok := matchTaxonomy(entities, value)
+			if !ok {
+				return nil, unknownTaxonomy(organizationTaxonomy, value, entities)
+			}
+			matches = []apiTaxonomy{entity}
+		}
+		for _, entity := range matches {
+			if !seen[entity.ID] {
+				seen[entity.ID] = true
+				selected = append(selected, entity)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// filterOrganizations returns the organizations whose ID is in ids and whose
+// name is in names, ignoring case; a nil map does not filter anything.
+func filterOrganizations(organizations []apiTaxonomy, ids map[int]bool, names map[string]bool) []apiTaxonomy {
+	filtered := []apiTaxonomy{}
+	for _, organization := range organizations {
+		if ids != nil && !ids[organization.ID] {
+			continue
+		}
+		if names != nil && !names[strings.ToLower(organization.Name)] {
+			continue
+		}
+		filtered = append(filtered, organization)
+	}
+	return filtered
+}
+
+// organizationQuals returns the organization IDs and the lower-case names in
+// the organization_id and organization_name quals; either is nil if there is
+// no such qual.
+func organizationQuals(d *plugin.QueryData) (map[int]bool, map[string]bool) {
+	var (
+		ids   map[int]bool
+		names map[string]bool
+	)
+	if value, ok := d.EqualsQuals["organization_id"]; ok {
+		ids = map[int]bool{}
+		for _, v := range qualValues(value) {
+			if _, ok := v.GetValue().(*proto.QualValue_Int64Value); ok {
+				ids[int(v.GetInt64Value())] = true
+			} else if id, err := strconv.Atoi(v.GetStringValue()); err == nil {
+				ids[id] = true
+			}
+		}
+	}
+	if value, ok := d.EqualsQuals["organization_name"]; ok {
+		names = map[string]bool{}
+		for _, v := range qualValues(value) {
+			names[strings.ToLower(v.GetStringValue())] = true
+		}
+	}
+	return ids, names
+}
+
+// qualValues returns the values of an IN (...) qual, or the value itself.
+func qualValues(value *proto.QualValue) []*proto.QualValue {
+	if list := value.GetListValue(); list != nil {
+		return list.Values
+	}
+	return []*proto.QualValue{value}
+}
+
+// needsOrganization tells whether the query selects or filters on the
+// organization columns, including the deprecated organization column of
+// satellite_host.
+func needsOrganization(d *plugin.QueryData) bool {
+	return isRequested(d, "organization", "organization_id", "organization_name")
+}
@@ -0,0 +1,70 @@
+package satellite
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestSelectOrganizations(t *testing.T) {
+	entities := []apiTaxonomy{
+		{ID: 1, Name: "ACME", Label: "acme"},
+		{ID: 2, Name: "Initech", Label: "initech"},
+		{ID: 3, Name: "Umbrella", Label: "umbrella"},
+	}
+
+	tests := []struct {
+		values   []string
+		expected []int
+		valid    bool
+	}{
+		{values: []string{"acme", "3"}, expected: []int{1, 3}, valid: true},
+		{values: []string{"Initech", "*"}, expected: []int{2, 1, 3}, valid: true},
+		{values: []string{"2", "initech"}, expected: []int{2}, valid: true},
+		{values: []string{"acme", "Hooli"}},
+	}
+
+	for _, test := range tests {
+		selected, err := selectOrganizations(entities, test.values)
+		t.Logf("%v: %v", test.values, err)
+		if (err == nil) != test.valid {
+			t.Fatalf("error: %v: expected valid %t, got error %v", test.values, test.valid, err)
+		}
+		if len(selected) != len(test.expected) {
+			t.Fatalf("error: %v: expected %v, got %v", test.values, test.expected, selected)
+		}
+		for i, entity := range selected {
+			if entity.ID != test.expected[i] {
+				t.Fatalf("error: %v: expected %v, got %v", test.values, test.expected, selected)
+			}
+		}
+	}
+}
+
+func TestOrganizationQuals(t *testing.T) {
+	d := &plugin.QueryData{
+		EqualsQuals: map[string]*proto.QualValue{
+			"organization_id": {Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{Values: []*proto.QualValue{
+				{Value: &proto.QualValue_Int64Value{Int64Value: 1}},
+				{Value: &proto.QualValue_Int64Value{Int64Value: 3}},
+			}}}},
+			"organization_name": {Value: &proto.QualValue_StringValue{StringValue: "Umbrella"}},
+		},
+	}
+
+	ids, names := organizationQuals(d)
+	organizations := filterOrganizations([]apiTaxonomy{
+		{ID: 1, Name: "ACME"},
+		{ID: 2, Name: "Initech"},
+		{ID: 3, Name: "umbrella"},
+	}, ids, names)
+	if len(organizations) != 1 || organizations[0].ID != 3 {
+		t.Fatalf("error: unexpected organizations %v", organizations)
+	}
+
+	ids, names = organizationQuals(&plugin.QueryData{})
+	if ids != nil || names != nil {
+		t.Fatalf("error: unexpected quals %v, %v", ids, names)
+	}
+}
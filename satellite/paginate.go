@@ -0,0 +1,146 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// DefaultPerPage is the page size used when per_page is not configured.
+const DefaultPerPage = 100
+
+// page is the envelope the Satellite API wraps around every page of results
+// returned by a collection endpoint; the results are usually a list.
+type page[R any] struct {
+	Total    int         `json:"total"`
+	Subtotal int         `json:"subtotal"`
+	Page     interface{} `json:"page"`
+	PerPage  int         `json:"per_page"`
+	Error    interface{} `json:"error"`
+	Search   interface{} `json:"search"`
+	Sort     struct {
+		By    string `json:"by"`
+		Order string `json:"order"`
+	} `json:"sort"`
+	Results R `json:"results"`
+}
+
+// current returns the index of the page. Note that the Satellite API returns
+// the page as an integer if there is no page?{page} query  parameter, and as
+// a string if you set one; thus we need to handle both cases.
+func (p *page[R]) current() (int, error) {
+	switch v := p.Page.(type) {
+	case int:
+		return v, nil
+	case int32:
+		return int(v), nil
+	case int64:
+		return int(v), nil
+	case float32:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	}
+	return 0, fmt.Errorf("unexpected type in pagination API result: %T", p.Page)
+}
+
+// paginate walks all the pages of the given collection endpoint and hands
+// each element to stream as soon as its page arrives; prepare can be used to
+// set path and query parameters and headers on each request. Pagination stops
+// when all pages have been retrieved, when the context is cancelled, or when
+// stream returns false (e.g. because the query's LIMIT has been reached).
+func paginate[T any](ctx context.Context, client *resty.Client, perPage int, url string, prepare func(*resty.Request), stream func(T) bool) error {
+	return paginateResults(ctx, client, perPage, url, prepare, func(results []T) []T {
+		return results
+	}, stream)
+}
+
+// paginateResults is like paginate, for the endpoints whose results are not a
+// list, e.g. /api/fact_values which returns the facts of each host by host
+// name: unfold turns the results of each page into the elements to stream,
+// which are those counted by the page size.
+func paginateResults[R any, T any](ctx context.Context, client *resty.Client, perPage int, url string, prepare func(*resty.Request), unfold func(R) []T, stream func(T) bool) error {
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	}
+
+	for index := 1; ; index++ {
+		if ctx.Err() != nil {
+			plugin.Logger(ctx).Debug("context done, exit")
+			return nil
+		}
+
+		result := &page[R]{}
+		request := client.
+			R().
+			SetContext(ctx).
+			SetQueryParam("page", fmt.Sprintf("%d", index)).
+			SetQueryParam("per_page", fmt.Sprintf("%d", perPage)).
+			SetResult(result)
+		if prepare != nil {
+			prepare(request)
+		}
+
+		response, err := request.Get(url)
+		if err != nil || response.IsError() {
+			if ctx.Err() != nil {
+				plugin.Logger(ctx).Debug("context done, exit")
+				return nil
+			}
+			plugin.Logger(ctx).Error("error performing request", "url", url, "status", response.Status(), "error", err, "response", string(response.Body()))
+			return requestError(response, err)
+		}
+		plugin.Logger(ctx).Debug("request successful", "url", url, "total", result.Total, "subtotal", result.Subtotal, "page", result.Page, "per page", result.PerPage, "response", utils.ToJSON(response.Body()))
+
+		items := unfold(result.Results)
+		for _, item := range items {
+			if ctx.Err() != nil {
+				plugin.Logger(ctx).Debug("context done, exit")
+				return nil
+			}
+			if !stream(item) {
+				plugin.Logger(ctx).Debug("no more items required, exit")
+				return nil
+			}
+		}
+
+		current, err := result.current()
+		if err != nil {
+			plugin.Logger(ctx).Debug("unsupported type in pagination", "type", fmt.Sprintf("%T", result.Page))
+			return err
+		}
+		// when searching, subtotal is the number of matching items
+		total := result.Subtotal
+		if total == 0 {
+			total = result.Total
+		}
+		if len(items) == 0 || result.PerPage*current >= total {
+			plugin.Logger(ctx).Debug("all pages retrieved", "subtotal", result.Subtotal, "total", result.Total)
+			return nil
+		}
+		plugin.Logger(ctx).Debug("retrieving next page", "page", index+1)
+	}
+}
+
+// requestError turns a failed request or an error response into an error that
+// reports the request URL and the HTTP status.
+func requestError(response *resty.Response, err error) error {
+	url := ""
+	if response != nil && response.Request != nil {
+		url = response.Request.URL
+	}
+	if err != nil {
+		return fmt.Errorf("request %q failed: %w", url, certificateError(err))
+	}
+	if response.StatusCode() == http.StatusNotFound {
+		return fmt.Errorf("request %q failed with status %d (%s): %w", url, response.StatusCode(), response.Status(), ErrNotFound)
+	}
+	return fmt.Errorf("request %q failed with status %d (%s)", url, response.StatusCode(), response.Status())
+}
@@ -0,0 +1,166 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/eko/gocache/v3/cache"
+	"github.com/eko/gocache/v3/store"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-hclog"
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/context_key"
+)
+
+func testContext() context.Context {
+	return context.WithValue(context.Background(), context_key.Logger, hclog.NewNullLogger())
+}
+
+// newTestClient returns a client for a server answering the requests it
+// accepts with the JSON response for their path, and all others with 404; a
+// nil accept accepts all requests.
+func newTestClient(t *testing.T, responses map[string]string, accept func(*http.Request) bool) *resty.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok || (accept != nil && !accept(r)) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(server.Close)
+	return resty.New().SetBaseURL(server.URL)
+}
+
+// newTestConnectionCache returns an empty connection cache, backed by the
+// same kind of store as the one the SDK creates.
+func newTestConnectionCache(t *testing.T) *connection.ConnectionCache {
+	ristrettoCache, err := ristretto.NewCache(&ristretto.Config{NumCounters: 1000, MaxCost: 100000, BufferItems: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ristrettoCache.Close)
+	return connection.NewConnectionCache("satellite", cache.New[any](store.NewRistretto(ristrettoCache)))
+}
+
+// newTestQueryData returns the data of a query with the given equality quals
+// against a connection with the given configuration.
+func newTestQueryData(t *testing.T, config satelliteConfig, quals map[string]*proto.QualValue) *plugin.QueryData {
+	connectionCache := newTestConnectionCache(t)
+	return &plugin.QueryData{
+		Connection:        &plugin.Connection{Name: "satellite", Config: config},
+		ConnectionManager: connection.NewManager(connectionCache),
+		ConnectionCache:   connectionCache,
+		EqualsQuals:       quals,
+	}
+}
+
+// newPagedServer serves total items in pages of per_page, returning the page
+// number as a string as Satellite does when the page parameter is set.
+func newPagedServer(total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/items" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		results := "["
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			if i > (page-1)*perPage {
+				results += ","
+			}
+			results += fmt.Sprintf(`{"id":%d}`, i)
+		}
+		results += "]"
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"total":%d,"subtotal":%d,"page":"%d","per_page":%d,"results":%s}`, total, total, page, perPage, results)
+	}))
+}
+
+func TestPaginate(t *testing.T) {
+	server := newPagedServer(25)
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	tests := []struct {
+		perPage  int
+		limit    int
+		expected int
+	}{
+		{perPage: 10, limit: -1, expected: 25},
+		{perPage: 5, limit: -1, expected: 25},
+		{perPage: 100, limit: -1, expected: 25},
+		{perPage: 10, limit: 12, expected: 12},
+	}
+
+	for _, test := range tests {
+		count := 0
+		err := paginate(testContext(), client, test.perPage, "/api/items", nil, func(i item) bool {
+			if i.ID != count {
+				t.Fatalf("error: expected item %d, got %d", count, i.ID)
+			}
+			count++
+			return count != test.limit
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("per page: %d, limit: %d, items: %d", test.perPage, test.limit, count)
+		if count != test.expected {
+			t.Fatalf("error: expected %d items, got %d", test.expected, count)
+		}
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	server := newPagedServer(25)
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	err := paginate(testContext(), client, 10, "/api/missing", nil, func(i struct{}) bool {
+		t.Fatal("error: no item expected")
+		return true
+	})
+	if err == nil {
+		t.Fatal("error: expected an error on status 404")
+	}
+	t.Logf("error: %v", err)
+}
+
+func TestPaginateCancelled(t *testing.T) {
+	server := newPagedServer(25)
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	ctx, cancel := context.WithCancel(testContext())
+	count := 0
+	err := paginate(ctx, client, 10, "/api/items", nil, func(i struct{}) bool {
+		count++
+		if count == 3 {
+			cancel()
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("error: expected 3 items, got %d", count)
+	}
+}
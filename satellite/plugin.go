@@ -8,14 +8,34 @@ import (
 )
 
 func Plugin(ctx context.Context) *plugin.Plugin {
+	tables := map[string]*plugin.Table{
+		"satellite_host":                    tableSatelliteHost(ctx),
+		"satellite_host_package":            tableSatelliteHostPackage(ctx),
+		"satellite_host_errata":             tableSatelliteHostErrata(ctx),
+		"satellite_host_interface":          tableSatelliteHostInterface(ctx),
+		"satellite_host_parameter":          tableSatelliteHostParameter(ctx),
+		"satellite_host_fact":               tableSatelliteHostFact(ctx),
+		"satellite_host_upgradable_package": tableSatelliteHostUpgradablePackage(ctx),
+		"satellite_host_cve":                tableSatelliteHostCVE(ctx),
+		"satellite_errata":                  tableSatelliteErrata(ctx),
+	}
 	p := &plugin.Plugin{
 		Name:             "steampipe-plugin-satellite",
 		DefaultTransform: transform.FromGo().NullIfZero(),
-		TableMap: map[string]*plugin.Table{
-			"satellite_host":         tableSatelliteHost(ctx),
-			"satellite_host_package": tableSatelliteHostPackage(ctx),
-			"satellite_host_errata":  tableSatelliteHostErrata(ctx),
+		TableMap:         tables,
+		// the table map is static, but building it is the only hook the SDK
+		// offers when a connection is loaded, so the configuration is
+		// validated and cached here; the SDK builds it only once, for the
+		// first connection, and an error would fail loading all of them, so
+		// an invalid configuration is only logged here and reported when the
+		// connection's client is created
+		TableMapFunc: func(ctx context.Context, d *plugin.TableMapData) (map[string]*plugin.Table, error) {
+			if _, err := getValidatedConfig(ctx, d.ConectionCache, d.Connection); err != nil {
+				plugin.Logger(ctx).Error("invalid connection configuration", "error", err)
+			}
+			return tables, nil
 		},
+		ConnectionConfigChangedFunc: connectionConfigChanged,
 		ConnectionConfigSchema: &plugin.ConnectionConfigSchema{
 			NewInstance: ConfigInstance,
 			Schema:      ConfigSchema,
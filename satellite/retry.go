@@ -0,0 +1,162 @@
+package satellite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed request is retried
+	// when max_retries is not configured.
+	DefaultMaxRetries = 3
+	// DefaultMinBackoff is the initial wait between retries when min_backoff
+	// is not configured.
+	DefaultMinBackoff = 1 * time.Second
+	// DefaultMaxBackoff is the maximum wait between retries when max_backoff
+	// is not configured; it also caps the wait requested via Retry-After.
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// retryConfig holds the retry behaviour of the Satellite client.
+type retryConfig struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// getRetryConfig reads the retry settings from the connection configuration,
+// falling back to the defaults for those that are not set.
+func getRetryConfig(config satelliteConfig) (retryConfig, error) {
+	result := retryConfig{
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+	if config.MaxRetries != nil {
+		if *config.MaxRetries < 0 {
+			return result, fmt.Errorf("invalid max_retries %d: it must not be negative", *config.MaxRetries)
+		}
+		result.MaxRetries = *config.MaxRetries
+	}
+	if config.MinBackoff != nil {
+		value, err := time.ParseDuration(*config.MinBackoff)
+		if err != nil {
+			return result, fmt.Errorf("invalid min_backoff %q: %w", *config.MinBackoff, err)
+		}
+		result.MinBackoff = value
+	}
+	if config.MaxBackoff != nil {
+		value, err := time.ParseDuration(*config.MaxBackoff)
+		if err != nil {
+			return result, fmt.Errorf("invalid max_backoff %q: %w", *config.MaxBackoff, err)
+		}
+		result.MaxBackoff = value
+	}
+	if result.MaxBackoff < result.MinBackoff {
+		return result, fmt.Errorf("invalid max_backoff %s: it must not be shorter than min_backoff %s", result.MaxBackoff, result.MinBackoff)
+	}
+	return result, nil
+}
+
+// setRetries enables retries with exponential backoff on the given client:
+// only GET requests are retried, when they fail because of a transient
+// network error or because Satellite is overloaded or unavailable.
+func setRetries(client *resty.Client, config retryConfig, logger hclog.Logger) *resty.Client {
+	return client.
+		SetRetryCount(config.MaxRetries).
+		SetRetryWaitTime(config.MinBackoff).
+		SetRetryMaxWaitTime(config.MaxBackoff).
+		SetRetryAfter(retryAfter).
+		AddRetryCondition(shouldRetry).
+		AddRetryHook(func(response *resty.Response, err error) {
+			status := 0
+			attempt := 0
+			url := ""
+			if response != nil {
+				status = response.StatusCode()
+				if response.Request != nil {
+					attempt = response.Request.Attempt
+					url = response.Request.URL
+				}
+			}
+			// resty runs the hooks after the last attempt too, but does not
+			// retry it
+			if attempt > config.MaxRetries {
+				logger.Error("request failed with a transient error, giving up", "url", url, "attempts", attempt, "status", status, "error", err)
+				return
+			}
+			logger.Warn("request failed with a transient error", "url", url, "attempt", attempt, "max retries", config.MaxRetries, "status", status, "error", err)
+		})
+}
+
+// shouldRetry tells whether a failed request can be retried: this is only
+// the case for idempotent GET requests failing with a retryable status or a
+// transport error, such as a reset or refused connection or a connection
+// closed early. Other errors, e.g. a response that cannot be decoded, would
+// fail the same way on every attempt, and so do those caused by a cancelled
+// context or by a TLS certificate problem.
+func shouldRetry(response *resty.Response, err error) bool {
+	if response == nil || response.Request == nil || response.Request.Method != http.MethodGet {
+		return false
+	}
+	if err != nil {
+		return isTransportError(err)
+	}
+	switch response.StatusCode() {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransportError tells whether the error is a transient failure of the
+// connection to the server.
+func isTransportError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || isCertificateError(err) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	// *url.Error implements net.Error whatever the error it wraps, so look
+	// into it
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryAfter returns the wait requested by the server via the Retry-After
+// header, either as a number of seconds or as an HTTP date; a zero duration
+// makes the client fall back to exponential backoff.
+func retryAfter(_ *resty.Client, response *resty.Response) (time.Duration, error) {
+	if response == nil {
+		return 0, nil
+	}
+	value := response.Header().Get("Retry-After")
+	if value == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, nil
+		}
+	}
+	return 0, nil
+}
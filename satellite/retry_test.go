@@ -0,0 +1,217 @@
+package satellite
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/go-hclog"
+)
+
+// newFlakyServer fails the first failures requests by invoking fail, then
+// answers with an empty JSON object; it returns the server and a pointer to
+// the number of requests received.
+func newFlakyServer(failures int32, fail func(w http.ResponseWriter)) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) <= failures {
+			fail(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	return server, &count
+}
+
+func newRetryClient(url string, maxRetries int, minBackoff, maxBackoff time.Duration) *resty.Client {
+	return setRetries(resty.New().SetBaseURL(url), retryConfig{
+		MaxRetries: maxRetries,
+		MinBackoff: minBackoff,
+		MaxBackoff: maxBackoff,
+	}, hclog.NewNullLogger())
+}
+
+func TestRetryStatus(t *testing.T) {
+	for _, status := range []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	} {
+		server, count := newFlakyServer(2, func(w http.ResponseWriter) {
+			w.WriteHeader(status)
+		})
+		client := newRetryClient(server.URL, 3, time.Millisecond, 10*time.Millisecond)
+		response, err := client.R().Get("/api/status")
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("status %d: %d requests, final status %d", status, *count, response.StatusCode())
+		if response.IsError() || *count != 3 {
+			t.Fatalf("error: expected success after 3 requests, got status %d after %d", response.StatusCode(), *count)
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	server, count := newFlakyServer(10, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 2, time.Millisecond, 10*time.Millisecond)
+	response, err := client.R().Get("/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode() != http.StatusServiceUnavailable || *count != 3 {
+		t.Fatalf("error: expected status 503 after 3 requests, got %d after %d", response.StatusCode(), *count)
+	}
+}
+
+func TestRetryExhaustedLog(t *testing.T) {
+	server, _ := newFlakyServer(10, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	output := &bytes.Buffer{}
+	logger := hclog.New(&hclog.LoggerOptions{Output: output, Level: hclog.Warn})
+	client := setRetries(resty.New().SetBaseURL(server.URL), retryConfig{
+		MaxRetries: 2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	}, logger)
+	if _, err := client.R().Get("/api/status"); err != nil {
+		t.Fatal(err)
+	}
+	log := output.String()
+	if strings.Count(log, "[WARN]") != 2 || strings.Count(log, "giving up") != 1 {
+		t.Fatalf("error: expected 2 retries and a final failure to be logged, got:\n%s", log)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	server, count := newFlakyServer(1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 3, time.Millisecond, 10*time.Millisecond)
+	response, err := client.R().Get("/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode() != http.StatusNotFound || *count != 1 {
+		t.Fatalf("error: expected status 404 after 1 request, got %d after %d", response.StatusCode(), *count)
+	}
+}
+
+func TestRetryNotDecodable(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "created_at": "not a time"}`))
+	}))
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 3, time.Millisecond, 10*time.Millisecond)
+	result := struct {
+		ID        int  `json:"id"`
+		CreatedAt Time `json:"created_at"`
+	}{}
+	_, err := client.R().SetResult(&result).Get("/api/hosts/1")
+	if err == nil {
+		t.Fatal("error: expected a decoding error")
+	}
+	if count != 1 {
+		t.Fatalf("error: expected 1 request, got %d", count)
+	}
+}
+
+func TestRetryOnlyGet(t *testing.T) {
+	server, count := newFlakyServer(1, func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 3, time.Millisecond, 10*time.Millisecond)
+	response, err := client.R().Post("/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode() != http.StatusServiceUnavailable || *count != 1 {
+		t.Fatalf("error: expected status 503 after 1 request, got %d after %d", response.StatusCode(), *count)
+	}
+}
+
+func TestRetryConnectionReset(t *testing.T) {
+	server, count := newFlakyServer(2, func(w http.ResponseWriter) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 3, time.Millisecond, 10*time.Millisecond)
+	response, err := client.R().Get("/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.IsError() || *count != 3 {
+		t.Fatalf("error: expected success after 3 requests, got status %d after %d", response.StatusCode(), *count)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	server, count := newFlakyServer(1, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	client := newRetryClient(server.URL, 3, time.Millisecond, 5*time.Second)
+	start := time.Now()
+	response, err := client.R().Get("/api/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	t.Logf("retried after %s", elapsed)
+	if response.IsError() || *count != 2 {
+		t.Fatalf("error: expected success after 2 requests, got status %d after %d", response.StatusCode(), *count)
+	}
+	if elapsed < time.Second {
+		t.Fatalf("error: expected to wait at least 1s as per Retry-After, waited %s", elapsed)
+	}
+}
+
+func TestRetryConfig(t *testing.T) {
+	tests := []struct {
+		config satelliteConfig
+		valid  bool
+	}{
+		{config: satelliteConfig{}, valid: true},
+		{config: satelliteConfig{MinBackoff: utils.PointerTo("500ms"), MaxBackoff: utils.PointerTo("1m")}, valid: true},
+		{config: satelliteConfig{MaxRetries: utils.PointerTo(-1)}, valid: false},
+		{config: satelliteConfig{MinBackoff: utils.PointerTo("soon")}, valid: false},
+		{config: satelliteConfig{MinBackoff: utils.PointerTo("1m"), MaxBackoff: utils.PointerTo("1s")}, valid: false},
+	}
+	for _, test := range tests {
+		_, err := getRetryConfig(test.config)
+		t.Logf("error: %v", err)
+		if (err == nil) != test.valid {
+			t.Fatalf("error: expected valid %t, got error %v", test.valid, err)
+		}
+	}
+}
@@ -2,34 +2,451 @@ package satellite
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
-// ParseNVRA returns name, version, release, arch from an RPM NVREA e.g.:
+// Package is the identity of an RPM package, as encoded in its file name or
+// in its NVRA or NVREA.
+type Package struct {
+	Name    string
+	Epoch   string
+	Version string
+	Release string
+	Arch    string
+	// Source is set for source packages, i.e. src and nosrc ones.
+	Source bool
+}
+
+// ParsePackage parses the identity of an RPM package from its NVRA or NVREA,
+// possibly with a trailing .rpm, e.g.:
 // - foo-1.0-1.i386.rpm returns foo, 1.0, 1, i386
-// - bar-9-123a.ia64.rpm returns bar, 9, 123a, 1, ia64
-// This is the Golang porting of the official implementation in Python's in rpmUtils.miscutils.
-func ParseNVRA(nvra string) (name string, ver string, rel string, arch string, err error) {
+// - bar-9-123a.ia64.rpm returns bar, 9, 123a, ia64
+// - bash-1:4.4.20-1.el8_4.x86_64 and 1:bash-4.4.20-1.el8_4.x86_64 both return
+// bash, epoch 1, 4.4.20, 1.el8_4, x86_64
+// An error is returned if any of name, version, release and arch is missing or
+// contains whitespace.
+// This started as the Golang porting of splitFilename() in Python's
+// rpmUtils.miscutils, which does not handle epochs.
+func ParsePackage(nvrea string) (Package, error) {
+	p := Package{}
+	s := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(nvrea), ".rpm"))
+	if s == "" {
+		return p, errors.New("invalid format: empty package")
+	}
+
+	// 1:bash-4.4.20-1.el8_4.x86_64
+	// ^>|
+	if i := strings.Index(s, ":"); i >= 0 && !strings.Contains(s[:i], "-") {
+		p.Epoch, s = s[:i], s[i+1:]
+	}
+
 	// tuned-profiles-cpu-partitioning-2.18.0-1.2.20220511git9fa66f19.el8fdp.noarch
 	//                                                                      ^----->|
-	archIndex := strings.LastIndex(nvra, ".")
+	archIndex := strings.LastIndex(s, ".")
 	if archIndex == -1 {
-		return "", "", "", "", errors.New("invalid format: no arch info")
+		return Package{}, fmt.Errorf("invalid format: no arch info in %q", nvrea)
+	}
+	p.Arch = s[archIndex+1:]
+	if p.Arch == "" || strings.ContainsAny(p.Arch, "-:") || hasSpace(p.Arch) {
+		return Package{}, fmt.Errorf("invalid format: invalid arch info in %q", nvrea)
 	}
-	arch = nvra[archIndex+1:]
 
 	// tuned-profiles-cpu-partitioning-2.18.0-1.2.20220511git9fa66f19.el8fdp.noarch
 	//                                       ^----------------------------->|
-	relIndex := strings.LastIndex(nvra[:archIndex], "-")
-	rel = nvra[relIndex+1 : archIndex]
+	relIndex := strings.LastIndex(s[:archIndex], "-")
+	if relIndex == -1 {
+		return Package{}, fmt.Errorf("invalid format: no release info in %q", nvrea)
+	}
+	p.Release = s[relIndex+1 : archIndex]
 
 	// tuned-profiles-cpu-partitioning-2.18.0-1.2.20220511git9fa66f19.el8fdp.noarch
 	//                                ^----->|
-	verIndex := strings.LastIndex(nvra[:relIndex], "-")
-	ver = nvra[verIndex+1 : relIndex]
+	verIndex := strings.LastIndex(s[:relIndex], "-")
+	if verIndex == -1 {
+		return Package{}, fmt.Errorf("invalid format: no version info in %q", nvrea)
+	}
+	p.Version = s[verIndex+1 : relIndex]
 
 	// tuned-profiles-cpu-partitioning-2.18.0-1.2.20220511git9fa66f19.el8fdp.noarch
 	// ------------------------------>|
-	name = nvra[:verIndex]
-	return
+	p.Name = s[:verIndex]
+
+	// bash-1:4.4.20-1.el8_4.x86_64
+	//      ^>|
+	if i := strings.Index(p.Version, ":"); i >= 0 {
+		if p.Epoch != "" {
+			return Package{}, fmt.Errorf("invalid format: more than one epoch in %q", nvrea)
+		}
+		p.Epoch, p.Version = p.Version[:i], p.Version[i+1:]
+	}
+
+	switch {
+	case p.Name == "" || strings.Contains(p.Name, ":") || hasSpace(p.Name):
+		return Package{}, fmt.Errorf("invalid format: invalid name in %q", nvrea)
+	case p.Version == "" || strings.Contains(p.Version, ":") || hasSpace(p.Version):
+		return Package{}, fmt.Errorf("invalid format: invalid version in %q", nvrea)
+	case p.Release == "" || strings.Contains(p.Release, ":") || hasSpace(p.Release):
+		return Package{}, fmt.Errorf("invalid format: invalid release in %q", nvrea)
+	}
+	for i := 0; i < len(p.Epoch); i++ {
+		if !isDigit(p.Epoch[i]) {
+			return Package{}, fmt.Errorf("invalid format: invalid epoch in %q", nvrea)
+		}
+	}
+	if p.Epoch == "" && strings.Contains(nvrea, ":") {
+		return Package{}, fmt.Errorf("invalid format: empty epoch in %q", nvrea)
+	}
+
+	p.Source = p.Arch == "src" || p.Arch == "nosrc"
+	return p, nil
+}
+
+// hasSpace returns whether s contains any whitespace, which cannot appear in
+// any part of an NVREA.
+func hasSpace(s string) bool {
+	return strings.IndexFunc(s, unicode.IsSpace) >= 0
+}
+
+// EVR returns the epoch, version and release of the package.
+func (p Package) EVR() EVR {
+	return EVR{Epoch: p.Epoch, Version: p.Version, Release: p.Release}
+}
+
+// String returns the NVREA of the package, as name-[epoch:]version-release.arch,
+// with the epoch only if it is not zero.
+func (p Package) String() string {
+	return p.Name + "-" + p.EVR().String() + "." + p.Arch
+}
+
+// NVRA returns the NVRA of the package, as name-version-release.arch, that is
+// without the epoch, as in the package lists of errata.
+func (p Package) NVRA() string {
+	return p.Name + "-" + p.Version + "-" + p.Release + "." + p.Arch
+}
+
+// ParseNVRA returns name, version, release, arch from an RPM NVRA or NVREA;
+// see ParsePackage.
+func ParseNVRA(nvra string) (name string, ver string, rel string, arch string, err error) {
+	p, err := ParsePackage(nvra)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	return p.Name, p.Version, p.Release, p.Arch, nil
+}
+
+// RPMVerCmp compares two version or release strings the way rpm does, and
+// returns -1, 0 or 1 if a is older than, equal to or newer than b; this is a
+// faithful Golang porting of rpmvercmp() in rpm's lib/rpmvercmp.c.
+// The strings are split into alphabetic and numeric segments, everything else
+// being a separator; numeric segments compare as numbers and are newer than
+// alphabetic ones, a tilde sorts before anything, even the end of the string
+// (1.0~rc1 < 1.0), and a caret sorts after the end of the string but before
+// anything else (1.0 < 1.0^git1 < 1.0.1).
+func RPMVerCmp(a string, b string) int {
+	// easy comparison to see if versions are identical
+	if a == b {
+		return 0
+	}
+
+	one, two := 0, 0
+	for one < len(a) || two < len(b) {
+		for one < len(a) && !isAlnum(a[one]) && a[one] != '~' && a[one] != '^' {
+			one++
+		}
+		for two < len(b) && !isAlnum(b[two]) && b[two] != '~' && b[two] != '^' {
+			two++
+		}
+
+		// handle the tilde separator, it sorts before everything else
+		if at(a, one) == '~' || at(b, two) == '~' {
+			if at(a, one) != '~' {
+				return 1
+			}
+			if at(b, two) != '~' {
+				return -1
+			}
+			one++
+			two++
+			continue
+		}
+
+		// handle the caret separator: the concept is the same as tilde,
+		// except that if one of the strings ends (base version), the other
+		// is considered as higher version
+		if at(a, one) == '^' || at(b, two) == '^' {
+			if one == len(a) {
+				return -1
+			}
+			if two == len(b) {
+				return 1
+			}
+			if a[one] != '^' {
+				return 1
+			}
+			if b[two] != '^' {
+				return -1
+			}
+			one++
+			two++
+			continue
+		}
+
+		// if we ran to the end of either, we are finished with the loop
+		if one == len(a) || two == len(b) {
+			break
+		}
+
+		// grab the first completely alpha or completely numeric segment
+		isNum := isDigit(a[one])
+		end1, end2 := one, two
+		if isNum {
+			for end1 < len(a) && isDigit(a[end1]) {
+				end1++
+			}
+			for end2 < len(b) && isDigit(b[end2]) {
+				end2++
+			}
+		} else {
+			for end1 < len(a) && isAlpha(a[end1]) {
+				end1++
+			}
+			for end2 < len(b) && isAlpha(b[end2]) {
+				end2++
+			}
+		}
+
+		// the segments are of different types: numeric segments are always
+		// newer than alpha segments
+		if end2 == two {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		segment1, segment2 := a[one:end1], b[two:end2]
+		if isNum {
+			// throw away any leading zeros, and the longest number wins
+			segment1 = strings.TrimLeft(segment1, "0")
+			segment2 = strings.TrimLeft(segment2, "0")
+			if len(segment1) > len(segment2) {
+				return 1
+			}
+			if len(segment2) > len(segment1) {
+				return -1
+			}
+		}
+		// don't return if they are equal, there might be more segments
+		if rc := strings.Compare(segment1, segment2); rc != 0 {
+			return rc
+		}
+
+		one, two = end1, end2
+	}
+
+	// all the segments compared identically, but the separators may differ
+	if one == len(a) && two == len(b) {
+		return 0
+	}
+	// whichever version still has characters left over wins
+	if one == len(a) {
+		return -1
+	}
+	return 1
+}
+
+// EVR is the epoch, version and release of a package.
+type EVR struct {
+	Epoch   string
+	Version string
+	Release string
+}
+
+// ParseEVR parses an [epoch:]version[-release] string.
+func ParseEVR(evr string) EVR {
+	result := EVR{}
+	if i := strings.Index(evr, ":"); i >= 0 {
+		result.Epoch, evr = evr[:i], evr[i+1:]
+	}
+	if i := strings.LastIndex(evr, "-"); i >= 0 {
+		result.Version, result.Release = evr[:i], evr[i+1:]
+	} else {
+		result.Version = evr
+	}
+	return result
+}
+
+// String returns the EVR as rpm prints it, i.e. as [epoch:]version-release,
+// with the epoch only if it is not zero.
+func (e EVR) String() string {
+	evr := e.Version
+	if e.Release != "" {
+		evr += "-" + e.Release
+	}
+	if epoch := strings.TrimLeft(e.Epoch, "0"); epoch != "" {
+		evr = epoch + ":" + evr
+	}
+	return evr
+}
+
+// Compare returns -1, 0 or 1 if e is older than, equal to or newer than
+// other: the epochs are compared first, a missing one being 0, then the
+// versions and then the releases, as in rpm's rpmVersionCompare().
+func (e EVR) Compare(other EVR) int {
+	if rc := RPMVerCmp(e.epoch(), other.epoch()); rc != 0 {
+		return rc
+	}
+	if rc := RPMVerCmp(e.Version, other.Version); rc != 0 {
+		return rc
+	}
+	return RPMVerCmp(e.Release, other.Release)
+}
+
+// SortKey returns a string that sorts byte-wise in the same order in which
+// RPMVerCmp sorts the EVRs, so that ORDER BY can be used on versions; only
+// numeric segments longer than 99 digits are not supported.
+func (e EVR) SortKey() string {
+	return sortKey(e.epoch()) + sortKey(e.Version) + sortKey(e.Release)
+}
+
+func (e EVR) epoch() string {
+	if e.Epoch == "" {
+		return "0"
+	}
+	return e.Epoch
+}
+
+// the classes of the tokens in a sort key, in the order in which RPMVerCmp
+// sorts them; they sort before any alphanumeric character.
+const (
+	keyTilde   = '0'
+	keyEnd     = '1'
+	keyCaret   = '2'
+	keyAlpha   = '3'
+	keyNumeric = '4'
+)
+
+// sortKey encodes a version or release string as a sequence of tokens, each
+// made of its class and of the segment, if any; numeric segments lose their
+// leading zeros and are prefixed by their length, so that longer numbers sort
+// after shorter ones. The key ends with the end of string token.
+func sortKey(version string) string {
+	var key strings.Builder
+	for i := 0; i < len(version); {
+		switch c := version[i]; {
+		case c == '~':
+			key.WriteByte(keyTilde)
+			i++
+		case c == '^':
+			key.WriteByte(keyCaret)
+			i++
+		case isDigit(c):
+			j := i
+			for j < len(version) && isDigit(version[j]) {
+				j++
+			}
+			number := strings.TrimLeft(version[i:j], "0")
+			key.WriteByte(keyNumeric)
+			key.WriteString(fmt.Sprintf("%02d", len(number)))
+			key.WriteString(number)
+			i = j
+		case isAlpha(c):
+			j := i
+			for j < len(version) && isAlpha(version[j]) {
+				j++
+			}
+			key.WriteByte(keyAlpha)
+			key.WriteString(version[i:j])
+			i = j
+		default:
+			// separators only delimit segments
+			i++
+		}
+	}
+	key.WriteByte(keyEnd)
+	return key.String()
+}
+
+// at returns the character at index i, or 0 at the end of the string.
+func at(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return 0
+}
+
+// these are ASCII-only, as rpm's risdigit(), risalpha() and risalnum().
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlpha(c byte) bool { return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') }
+func isAlnum(c byte) bool { return isDigit(c) || isAlpha(c) }
+
+// Release is what can be decoded of a package release string, such as
+// 8.module+el8.4.0+14872+9efa52a3, 4.el8_4.1 or 1.el8ost.
+type Release struct {
+	// DistTag is the distribution tag, e.g. el8_4, el8ost or, for modular
+	// packages, module+el8.4.0+14872+9efa52a3.
+	DistTag string
+	// DistMajor is the major release of the distribution, e.g. 8.
+	DistMajor *int
+	// DistMinor is the minor release of the distribution, if the package was
+	// built for a specific one, e.g. 4 in el8_4 and in module+el8.4.0.
+	DistMinor *int
+	// ZStream is set for packages built for the z-stream of a minor release,
+	// e.g. el8_4.
+	ZStream bool
+	// ModuleBuild is the ID of the module build that produced the package,
+	// e.g. 14872 in module+el8.4.0+14872+9efa52a3.
+	ModuleBuild *int
+	// Product is the suffix of the layered product the package was built
+	// for, e.g. ost in el8ost, ae in el8ae or fdp in el8fdp.
+	Product string
+}
+
+var (
+	// module+el8.4.0+14872+9efa52a3
+	moduleRelease = regexp.MustCompile(`(?:^|\.)(module\+(el|fc)(\d+)\.(\d+)\.\d+\+(\d+)\+[0-9a-f]+)`)
+	// el8, el8_4, el8ost, fc38
+	distRelease = regexp.MustCompile(`^(el|fc)(\d+)(?:_(\d+))?([a-z]*)$`)
+)
+
+// ParseRelease decodes the distribution and modularity metadata in a package
+// release string; the fields that cannot be decoded are left empty.
+func ParseRelease(release string) Release {
+	r := Release{}
+	if m := moduleRelease.FindStringSubmatch(release); m != nil {
+		r.DistTag = m[1]
+		r.DistMajor = atoi(m[3])
+		r.DistMinor = atoi(m[4])
+		r.ModuleBuild = atoi(m[5])
+		return r
+	}
+
+	// the dist tag is the last matching segment, e.g. 2.el8_4.1
+	segments := strings.FieldsFunc(release, func(c rune) bool { return c == '.' || c == '+' })
+	for i := len(segments) - 1; i >= 0; i-- {
+		if m := distRelease.FindStringSubmatch(segments[i]); m != nil {
+			r.DistTag = segments[i]
+			r.DistMajor = atoi(m[2])
+			if m[3] != "" {
+				r.DistMinor = atoi(m[3])
+				r.ZStream = true
+			}
+			r.Product = m[4]
+			break
+		}
+	}
+	return r
+}
+
+// atoi returns a pointer to the value of a string of digits.
+func atoi(s string) *int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+	return &n
 }
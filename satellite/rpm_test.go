@@ -1,6 +1,9 @@
 package satellite
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestParseNVREA(t *testing.T) {
 	for _, nvrea := range []string{
@@ -977,7 +980,285 @@ func TestParseNVREA(t *testing.T) {
 		"shim-x64-15.6-1.el8.x86_64",
 		"yum-4.4.2-11.el8.noarch",
 	} {
-		n, v, r, a, _ := ParseNVRA(nvrea)
+		n, v, r, a, err := ParseNVRA(nvrea)
+		if err != nil {
+			t.Fatal(err)
+		}
 		t.Logf("n: %q, v: %q, r: %q, a: %q", n, v, r, a)
 	}
 }
+
+// rpmVerCmpTests is the corpus of rpm's own test suite, in tests/rpmvercmp.at.
+var rpmVerCmpTests = []struct {
+	a        string
+	b        string
+	expected int
+}{
+	{"1.0", "1.0", 0},
+	{"1.0", "2.0", -1},
+	{"2.0", "1.0", 1},
+	{"2.0.1", "2.0.1", 0},
+	{"2.0", "2.0.1", -1},
+	{"2.0.1", "2.0", 1},
+	{"2.0.1a", "2.0.1a", 0},
+	{"2.0.1a", "2.0.1", 1},
+	{"2.0.1", "2.0.1a", -1},
+	{"5.5p1", "5.5p1", 0},
+	{"5.5p1", "5.5p2", -1},
+	{"5.5p2", "5.5p1", 1},
+	{"5.5p10", "5.5p10", 0},
+	{"5.5p1", "5.5p10", -1},
+	{"5.5p10", "5.5p1", 1},
+	{"10xyz", "10.1xyz", -1},
+	{"10.1xyz", "10xyz", 1},
+	{"xyz10", "xyz10", 0},
+	{"xyz10", "xyz10.1", -1},
+	{"xyz10.1", "xyz10", 1},
+	{"xyz.4", "xyz.4", 0},
+	{"xyz.4", "8", -1},
+	{"8", "xyz.4", 1},
+	{"xyz.4", "2", -1},
+	{"2", "xyz.4", 1},
+	{"5.5p2", "5.6p1", -1},
+	{"5.6p1", "5.5p2", 1},
+	{"5.6p1", "6.5p1", -1},
+	{"6.5p1", "5.6p1", 1},
+	{"6.0.rc1", "6.0", 1},
+	{"6.0", "6.0.rc1", -1},
+	{"10b2", "10a1", 1},
+	{"10a2", "10b2", -1},
+	{"1.0aa", "1.0aa", 0},
+	{"1.0a", "1.0aa", -1},
+	{"1.0aa", "1.0a", 1},
+	{"10.0001", "10.0001", 0},
+	{"10.0001", "10.1", 0},
+	{"10.1", "10.0001", 0},
+	{"10.0001", "10.0039", -1},
+	{"10.0039", "10.0001", 1},
+	{"4.999.9", "5.0", -1},
+	{"5.0", "4.999.9", 1},
+	{"20101121", "20101121", 0},
+	{"20101121", "20101122", -1},
+	{"20101122", "20101121", 1},
+	{"2_0", "2_0", 0},
+	{"2.0", "2_0", 0},
+	{"2_0", "2.0", 0},
+	{"a", "a", 0},
+	{"a+", "a+", 0},
+	{"a+", "a_", 0},
+	{"a_", "a+", 0},
+	{"+a", "+a", 0},
+	{"+a", "_a", 0},
+	{"_a", "+a", 0},
+	{"+_", "+_", 0},
+	{"_+", "+_", 0},
+	{"_+", "_+", 0},
+	{"+", "_", 0},
+	{"_", "+", 0},
+	{"1.0~rc1", "1.0~rc1", 0},
+	{"1.0~rc1", "1.0", -1},
+	{"1.0", "1.0~rc1", 1},
+	{"1.0~rc1", "1.0~rc2", -1},
+	{"1.0~rc2", "1.0~rc1", 1},
+	{"1.0~rc1~git123", "1.0~rc1~git123", 0},
+	{"1.0~rc1~git123", "1.0~rc1", -1},
+	{"1.0~rc1", "1.0~rc1~git123", 1},
+	{"1.0^", "1.0^", 0},
+	{"1.0^", "1.0", 1},
+	{"1.0", "1.0^", -1},
+	{"1.0^git1", "1.0^git1", 0},
+	{"1.0^git1", "1.0", 1},
+	{"1.0", "1.0^git1", -1},
+	{"1.0^git1", "1.0^git2", -1},
+	{"1.0^git2", "1.0^git1", 1},
+	{"1.0^git1", "1.01", -1},
+	{"1.01", "1.0^git1", 1},
+	{"1.0^20160101", "1.0^20160101", 0},
+	{"1.0^20160101", "1.0.1", -1},
+	{"1.0.1", "1.0^20160101", 1},
+	{"1.0^20160101^git1", "1.0^20160101^git1", 0},
+	{"1.0^20160102", "1.0^20160101^git1", 1},
+	{"1.0^20160101^git1", "1.0^20160102", -1},
+	{"1.0~rc1^git1", "1.0~rc1^git1", 0},
+	{"1.0~rc1^git1", "1.0~rc1", 1},
+	{"1.0~rc1", "1.0~rc1^git1", -1},
+	{"1.0^git1~pre", "1.0^git1~pre", 0},
+	{"1.0^git1", "1.0^git1~pre", 1},
+	{"1.0^git1~pre", "1.0^git1", -1},
+	{"1b.fc17", "1b.fc17", 0},
+	{"1b.fc17", "1.fc17", -1},
+	{"1.fc17", "1b.fc17", 1},
+	{"1g.fc17", "1g.fc17", 0},
+	{"1g.fc17", "1.fc17", 1},
+	{"1.fc17", "1g.fc17", -1},
+	// non-ASCII characters are separators
+	{"1.1.α", "1.1.α", 0},
+	{"1.1.α", "1.1.β", 0},
+	{"1.1.αα", "1.1.α", 0},
+	{"1.1.α", "1.1.αα", 0},
+	// module stream releases
+	{"8.module+el8.4.0+14872+9efa52a3", "8.module+el8.4.0+14524+f996d8af", 1},
+	{"8.module+el8.4.0+14872+9efa52a3", "8.module+el8.10.0+1234+abcdef12", -1},
+	{"305.49.1.el8_4", "305.45.1.el8_4", 1},
+	{"305.45.1.el8_4", "305.el8", 1},
+}
+
+func TestRPMVerCmp(t *testing.T) {
+	for _, test := range rpmVerCmpTests {
+		if actual := RPMVerCmp(test.a, test.b); actual != test.expected {
+			t.Fatalf("error: rpmvercmp(%q, %q): expected %d, got %d", test.a, test.b, test.expected, actual)
+		}
+		// the sort keys must sort the same way
+		if actual := strings.Compare(sortKey(test.a), sortKey(test.b)); actual != test.expected {
+			t.Fatalf("error: sort keys of %q and %q: expected %d, got %d (%q, %q)", test.a, test.b, test.expected, actual, sortKey(test.a), sortKey(test.b))
+		}
+	}
+}
+
+func TestCompareEVR(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"4.18.0-305.49.1.el8_4", "4.18.0-305.45.1.el8_4", 1},
+		{"1:1.0-1", "2.0-1", 1},
+		{"0:2.0-1", "2.0-1", 0},
+		{"2.0-1", "1:1.0-1", -1},
+		{"1.0-1", "1.0-1.el8", -1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"10:1.0-1", "9:1.0-1", 1},
+	}
+	for _, test := range tests {
+		a, b := ParseEVR(test.a), ParseEVR(test.b)
+		if actual := a.Compare(b); actual != test.expected {
+			t.Fatalf("error: %q vs %q: expected %d, got %d", test.a, test.b, test.expected, actual)
+		}
+		if actual := strings.Compare(a.SortKey(), b.SortKey()); actual != test.expected {
+			t.Fatalf("error: sort keys of %q and %q: expected %d, got %d", test.a, test.b, test.expected, actual)
+		}
+	}
+
+	if evr := ParseEVR("1:4.18.0-305.el8"); evr != (EVR{Epoch: "1", Version: "4.18.0", Release: "305.el8"}) || evr.String() != "1:4.18.0-305.el8" {
+		t.Fatalf("error: unexpected EVR %+v", evr)
+	}
+	if evr := ParseEVR("0:1.0-1"); evr.String() != "1.0-1" {
+		t.Fatalf("error: unexpected EVR string %q", evr.String())
+	}
+}
+
+func TestParsePackage(t *testing.T) {
+	tests := []struct {
+		nvrea    string
+		expected Package
+		err      bool
+	}{
+		{nvrea: "foo-1.0-1.i386.rpm", expected: Package{Name: "foo", Version: "1.0", Release: "1", Arch: "i386"}},
+		{nvrea: "bar-9-123a.ia64.rpm", expected: Package{Name: "bar", Version: "9", Release: "123a", Arch: "ia64"}},
+		{nvrea: "bash-1:4.4.20-1.el8_4.x86_64", expected: Package{Name: "bash", Epoch: "1", Version: "4.4.20", Release: "1.el8_4", Arch: "x86_64"}},
+		{nvrea: "1:bash-4.4.20-1.el8_4.x86_64", expected: Package{Name: "bash", Epoch: "1", Version: "4.4.20", Release: "1.el8_4", Arch: "x86_64"}},
+		{nvrea: "containers-common-1.2.2-8.module+el8.4.0+14872+9efa52a3.x86_64", expected: Package{Name: "containers-common", Version: "1.2.2", Release: "8.module+el8.4.0+14872+9efa52a3", Arch: "x86_64"}},
+		{nvrea: "bash-4.4.20-1.el8_4.src.rpm", expected: Package{Name: "bash", Version: "4.4.20", Release: "1.el8_4", Arch: "src", Source: true}},
+		{nvrea: "kernel-4.18.0-305.el8.nosrc", expected: Package{Name: "kernel", Version: "4.18.0", Release: "305.el8", Arch: "nosrc", Source: true}},
+		{nvrea: "", err: true},
+		{nvrea: "bash", err: true},
+		{nvrea: "bash.x86_64", err: true},
+		{nvrea: "bash-4.4.20.x86_64", err: true},
+		{nvrea: "-4.4.20-1.x86_64", err: true},
+		{nvrea: "bash--1.x86_64", err: true},
+		{nvrea: "bash-4.4.20-.x86_64", err: true},
+		{nvrea: "bash-4.4.20-1.", err: true},
+		{nvrea: "bash-4.4.20-1", err: true},
+		{nvrea: "1:bash-1:4.4.20-1.x86_64", err: true},
+		{nvrea: "bash-x:4.4.20-1.x86_64", err: true},
+		{nvrea: ":bash-4.4.20-1.x86_64", err: true},
+		{nvrea: " bash-4.4.20-1.el8_4.x86_64.rpm ", expected: Package{Name: "bash", Version: "4.4.20", Release: "1.el8_4", Arch: "x86_64"}},
+		{nvrea: "0-0-0. .rpm", err: true},
+		{nvrea: "bash-4.4.20-1 .x86_64", err: true},
+		{nvrea: "bash-4.4 .20-1.x86_64", err: true},
+		{nvrea: "ba sh-4.4.20-1.x86_64", err: true},
+	}
+	for _, test := range tests {
+		actual, err := ParsePackage(test.nvrea)
+		if (err != nil) != test.err {
+			t.Fatalf("error: %q: unexpected error %v", test.nvrea, err)
+		}
+		if actual != test.expected {
+			t.Fatalf("error: %q: expected %+v, got %+v", test.nvrea, test.expected, actual)
+		}
+	}
+}
+
+func FuzzParsePackage(f *testing.F) {
+	for _, seed := range []string{
+		"foo-1.0-1.i386.rpm",
+		"bash-1:4.4.20-1.el8_4.x86_64",
+		"1:bash-4.4.20-1.el8_4.x86_64",
+		"containers-common-1.2.2-8.module+el8.4.0+14872+9efa52a3.x86_64",
+		"bash-4.4.20-1.el8_4.src.rpm",
+		"bash",
+		"-.-",
+		":-:-:.:",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, nvrea string) {
+		p, err := ParsePackage(nvrea)
+		if err != nil {
+			return
+		}
+		if p.Name == "" || p.Version == "" || p.Release == "" || p.Arch == "" {
+			t.Fatalf("error: %q: incomplete package %+v", nvrea, p)
+		}
+		// the package must survive a round trip through its NVREA
+		again, err := ParsePackage(p.String())
+		if err != nil {
+			t.Fatalf("error: %q: cannot parse %q again: %v", nvrea, p.String(), err)
+		}
+		if again.Name != p.Name || again.Arch != p.Arch || again.Source != p.Source || again.EVR().Compare(p.EVR()) != 0 {
+			t.Fatalf("error: %q: expected %+v, got %+v", nvrea, p, again)
+		}
+	})
+}
+
+func TestParseRelease(t *testing.T) {
+	tests := []struct {
+		release     string
+		distTag     string
+		distMajor   int
+		distMinor   int
+		zStream     bool
+		moduleBuild int
+		product     string
+	}{
+		{release: "8.module+el8.4.0+14872+9efa52a3", distTag: "module+el8.4.0+14872+9efa52a3", distMajor: 8, distMinor: 4, moduleBuild: 14872},
+		{release: "1.module+el8.10.0+22417+6b0b5dbb", distTag: "module+el8.10.0+22417+6b0b5dbb", distMajor: 8, distMinor: 10, moduleBuild: 22417},
+		{release: "4.el8_4.1", distTag: "el8_4", distMajor: 8, distMinor: 4, zStream: true},
+		{release: "1.el8ost", distTag: "el8ost", distMajor: 8, product: "ost"},
+		{release: "11.el8ost.1", distTag: "el8ost", distMajor: 8, product: "ost"},
+		{release: "1.el8ae", distTag: "el8ae", distMajor: 8, product: "ae"},
+		{release: "1.2.20220511git9fa66f19.el8fdp", distTag: "el8fdp", distMajor: 8, product: "fdp"},
+		{release: "1.el8sat", distTag: "el8sat", distMajor: 8, product: "sat"},
+		{release: "151.el8", distTag: "el8", distMajor: 8},
+		{release: "80.0.el8_4", distTag: "el8_4", distMajor: 8, distMinor: 4, zStream: true},
+		{release: "1.gitbfb6bed.el8_3", distTag: "el8_3", distMajor: 8, distMinor: 3, zStream: true},
+		{release: "2.fc38", distTag: "fc38", distMajor: 38},
+		{release: "12"},
+		{release: "0.17.20191104git1c2f876"},
+	}
+	value := func(n *int) int {
+		if n == nil {
+			return 0
+		}
+		return *n
+	}
+	for _, test := range tests {
+		r := ParseRelease(test.release)
+		if r.DistTag != test.distTag || value(r.DistMajor) != test.distMajor || value(r.DistMinor) != test.distMinor || r.ZStream != test.zStream || value(r.ModuleBuild) != test.moduleBuild || r.Product != test.product {
+			t.Fatalf("error: %q: unexpected release %+v", test.release, r)
+		}
+	}
+	if r := ParseRelease("1.el8"); r.DistMinor != nil || r.ModuleBuild != nil {
+		t.Fatalf("error: unexpected minor release or module build in %+v", r)
+	}
+}
@@ -0,0 +1,192 @@
+package satellite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// searchKind tells the translator how a qual value must be rendered in a
+// Foreman scoped-search term.
+type searchKind int
+
+const (
+	searchString searchKind = iota
+	searchInt
+	searchBool
+	searchTime
+)
+
+// searchColumn maps a table column onto a field in Foreman's scoped-search
+// language; Values, if present, translates the column values (e.g. status
+// labels) into the keywords the search field expects.
+type searchColumn struct {
+	Column    string
+	Field     string
+	Kind      searchKind
+	Operators []string
+	Values    map[string]string
+}
+
+type searchColumns []searchColumn
+
+// KeyColumns returns the optional key columns that can be pushed down to the
+// API as a scoped search. The Steampipe SDK only accepts comparison operators
+// in key column declarations, so LIKE and ILIKE are never pushed down and are
+// filtered by Postgres.
+func (s searchColumns) KeyColumns() plugin.KeyColumnSlice {
+	keyColumns := plugin.KeyColumnSlice{}
+	for _, column := range s {
+		keyColumns = append(keyColumns, &plugin.KeyColumn{
+			Name:      column.Column,
+			Operators: column.Operators,
+			Require:   plugin.Optional,
+		})
+	}
+	return keyColumns
+}
+
+// Search translates the given quals into a Foreman scoped-search query, e.g.
+//
+//	name = "web01.example.com" and environment = "production"
+//
+// Quals that cannot be expressed faithfully are left out: this only widens
+// the result set, which is filtered again by Steampipe anyway.
+func (s searchColumns) Search(quals plugin.KeyColumnQualMap) string {
+	terms := []string{}
+	for _, column := range s {
+		columnQuals, ok := quals[column.Column]
+		if !ok || columnQuals == nil {
+			continue
+		}
+		for _, qual := range columnQuals.Quals {
+			if term, ok := column.term(qual.Operator, qual.Value); ok {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return strings.Join(terms, " and ")
+}
+
+// term renders a single qual as a scoped-search term.
+func (c searchColumn) term(operator string, value *proto.QualValue) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+
+	// IN (...) and NOT IN (...) come through as = and <> with a list value
+	if list := value.GetListValue(); list != nil {
+		if c.Kind == searchTime {
+			return "", false
+		}
+		values := []string{}
+		for _, v := range list.Values {
+			rendered, ok := c.value(v)
+			if !ok {
+				return "", false
+			}
+			values = append(values, rendered)
+		}
+		if len(values) == 0 {
+			return "", false
+		}
+		switch operator {
+		case "=":
+			return fmt.Sprintf("%s ^ (%s)", c.Field, strings.Join(values, ", ")), true
+		case "<>":
+			return fmt.Sprintf("%s !^ (%s)", c.Field, strings.Join(values, ", ")), true
+		}
+		return "", false
+	}
+
+	if c.Kind == searchTime {
+		return c.timeTerm(operator, value)
+	}
+
+	switch operator {
+	case "=", "<", "<=", ">", ">=":
+		// operators are the same in both languages
+	case "<>":
+		operator = "!="
+	default:
+		return "", false
+	}
+
+	rendered, ok := c.value(value)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s %s", c.Field, operator, rendered), true
+}
+
+// timeTerm renders a qual on a time as a scoped-search term. Scoped search
+// only has a one-second resolution, so the bounds are widened to the whole
+// seconds around the qual value and an equality becomes a one-second range:
+// this only returns a superset of the matching rows, which Postgres filters
+// again.
+func (c searchColumn) timeTerm(operator string, value *proto.QualValue) (string, bool) {
+	var t time.Time
+	if ts := value.GetTimestampValue(); ts != nil {
+		t = ts.AsTime()
+	} else if parsed, err := parseTime(value.GetStringValue()); err == nil && !parsed.IsZero() {
+		t = parsed
+	} else {
+		return "", false
+	}
+	floor := t.UTC().Truncate(time.Second)
+	ceil := floor
+	if !floor.Equal(t) {
+		ceil = floor.Add(time.Second)
+	}
+	render := func(t time.Time) string {
+		return quote(t.Format(layout))
+	}
+
+	switch operator {
+	case "=":
+		return fmt.Sprintf("%s >= %s and %s < %s", c.Field, render(floor), c.Field, render(floor.Add(time.Second))), true
+	case ">", ">=":
+		return fmt.Sprintf("%s >= %s", c.Field, render(floor)), true
+	case "<", "<=":
+		return fmt.Sprintf("%s <= %s", c.Field, render(ceil)), true
+	}
+	return "", false
+}
+
+// value renders a single qual value according to the column kind.
+func (c searchColumn) value(value *proto.QualValue) (string, bool) {
+	switch c.Kind {
+	case searchString:
+		v := value.GetStringValue()
+		if c.Values != nil {
+			keyword, ok := c.Values[strings.ToLower(v)]
+			if !ok {
+				return "", false
+			}
+			v = keyword
+		}
+		return quote(v), true
+	case searchInt:
+		if _, ok := value.GetValue().(*proto.QualValue_Int64Value); ok {
+			return strconv.FormatInt(value.GetInt64Value(), 10), true
+		}
+		if v, err := strconv.ParseInt(value.GetStringValue(), 10, 64); err == nil {
+			return strconv.FormatInt(v, 10), true
+		}
+	case searchBool:
+		if _, ok := value.GetValue().(*proto.QualValue_BoolValue); ok {
+			return strconv.FormatBool(value.GetBoolValue()), true
+		}
+	}
+	return "", false
+}
+
+// quote wraps a value in double quotes, escaping any backslashes and double
+// quotes it contains.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
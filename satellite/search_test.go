@@ -0,0 +1,142 @@
+package satellite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func stringQual(v string) *proto.QualValue {
+	return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: v}}
+}
+
+func listQual(values ...string) *proto.QualValue {
+	list := &proto.QualValueList{}
+	for _, v := range values {
+		list.Values = append(list.Values, stringQual(v))
+	}
+	return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: list}}
+}
+
+func qualMap(qs ...*quals.Qual) plugin.KeyColumnQualMap {
+	m := plugin.KeyColumnQualMap{}
+	for _, q := range qs {
+		if _, ok := m[q.Column]; !ok {
+			m[q.Column] = &plugin.KeyColumnQuals{Name: q.Column}
+		}
+		m[q.Column].Quals = append(m[q.Column].Quals, q)
+	}
+	return m
+}
+
+func TestSearch(t *testing.T) {
+	created := time.Date(2022, 11, 28, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		quals    plugin.KeyColumnQualMap
+		expected string
+	}{
+		{
+			quals:    qualMap(),
+			expected: "",
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "name", Operator: "=", Value: stringQual("web01.example.com")}),
+			expected: `name = "web01.example.com"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "name", Operator: "<>", Value: stringQual("web01.example.com")}),
+			expected: `name != "web01.example.com"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "name", Operator: "~~", Value: stringQual("web%.example.com")}),
+			expected: ``,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "environment", Operator: "~~*", Value: stringQual("prod_ction")}),
+			expected: ``,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "name", Operator: "=", Value: listQual("a", "b")}),
+			expected: `name ^ ("a", "b")`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "name", Operator: "<>", Value: listQual("a", "b")}),
+			expected: `name !^ ("a", "b")`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "host_group_title", Operator: "=", Value: stringQual(`RHEL 8/"Base" \ Web`)}),
+			expected: `hostgroup_title = "RHEL 8/\"Base\" \\ Web"`,
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "operating_system", Operator: "=", Value: stringQual("RedHat 8.4")},
+				&quals.Qual{Column: "architecture", Operator: "=", Value: stringQual("x86_64")},
+			),
+			expected: `os_title = "RedHat 8.4" and architecture = "x86_64"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "errata_status", Operator: "=", Value: stringQual("Security errata applicable")}),
+			expected: `errata_status = "security_needed"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "errata_status", Operator: "=", Value: stringQual("no such status")}),
+			expected: ``,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "managed", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: true}}}),
+			expected: `managed = true`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "managed", Operator: "<>", Value: &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: false}}}),
+			expected: `managed != false`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "id", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_Int64Value{Int64Value: 42}}}),
+			expected: `id = 42`,
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "created_at", Operator: ">", Value: &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(created)}}},
+				&quals.Qual{Column: "created_at", Operator: "<=", Value: stringQual("2022-12-31 23:59:59 UTC")},
+			),
+			expected: `created_at >= "2022-11-28 12:00:00 UTC" and created_at <= "2022-12-31 23:59:59 UTC"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "created_at", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(created.Add(250 * time.Millisecond))}}}),
+			expected: `created_at >= "2022-11-28 12:00:00 UTC" and created_at < "2022-11-28 12:00:01 UTC"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "created_at", Operator: "<", Value: &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(created.Add(250 * time.Millisecond))}}}),
+			expected: `created_at <= "2022-11-28 12:00:01 UTC"`,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "created_at", Operator: ">", Value: stringQual("yesterday")}),
+			expected: ``,
+		},
+		{
+			quals:    qualMap(&quals.Qual{Column: "uptime_seconds", Operator: ">", Value: stringQual("3600")}),
+			expected: ``,
+		},
+	}
+
+	for _, test := range tests {
+		actual := hostSearchColumns.Search(test.quals)
+		t.Logf("testing: %q", test.expected)
+		if actual != test.expected {
+			t.Fatalf("error: expected %q, got %q", test.expected, actual)
+		}
+	}
+}
+
+func TestSearchKeyColumns(t *testing.T) {
+	for _, keyColumn := range hostSearchColumns.KeyColumns() {
+		if errs := keyColumn.Validate(); len(errs) > 0 {
+			t.Fatalf("error: invalid key column %q: %v", keyColumn.Name, errs)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteErrata(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_errata",
+		Description: "Red Hat Satellite Errata",
+		Columns: []*plugin.Column{
+			{
+				Name:        "id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the errata.",
+				Transform:   transform.FromField("ID"),
+			},
+			{
+				Name:        "pulp_id",
+				Type:        proto.ColumnType_STRING,
+				Description: "The pulp ID of the errata.",
+				Transform:   transform.FromField("PulpID"),
+			},
+			{
+				Name:        "title",
+				Type:        proto.ColumnType_STRING,
+				Description: "The title of the errata.",
+				Transform:   transform.FromField("Title"),
+			},
+			{
+				Name:        "errata_id",
+				Type:        proto.ColumnType_STRING,
+				Description: "The ID of the errata.",
+				Transform:   transform.FromField("ErrataID"),
+			},
+			{
+				Name:        "issued_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The time when the errata was issued.",
+				Transform:   transform.FromField("Issued").Transform(ToTimestamp),
+			},
+			{
+				Name:        "updated_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The time when the errata was updated.",
+				Transform:   transform.FromField("Updated").Transform(ToTimestamp),
+			},
+			{
+				Name:        "severity",
+				Type:        proto.ColumnType_STRING,
+				Description: "The severity of the errata.",
+				Transform:   transform.FromField("Severity"),
+			},
+			{
+				Name:        "description",
+				Type:        proto.ColumnType_STRING,
+				Description: "The description of the errata.",
+				Transform:   transform.FromField("Description"),
+			},
+			{
+				Name:        "solution",
+				Type:        proto.ColumnType_STRING,
+				Description: "The solution of the errata.",
+				Transform:   transform.FromField("Solution"),
+			},
+			{
+				Name:        "summary",
+				Type:        proto.ColumnType_STRING,
+				Description: "The summary of the errata.",
+				Transform:   transform.FromField("Summary"),
+			},
+			{
+				Name:        "reboot_suggested",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether a reboot is suggested to fix the errata.",
+				Transform:   transform.FromField("RebootSuggested"),
+			},
+			{
+				Name:        "uuid",
+				Type:        proto.ColumnType_STRING,
+				Description: "The UUID of the errata.",
+				Transform:   transform.FromField("UUID"),
+			},
+			{
+				Name:        "name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the errata.",
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "type",
+				Type:        proto.ColumnType_STRING,
+				Description: "The type of the errata.",
+				Transform:   transform.FromField("Type"),
+			},
+			{
+				Name:        "hosts_available_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of hosts on which the errata is installable.",
+				Transform:   transform.FromField("HostsAvailableCount"),
+			},
+			{
+				Name:        "hosts_applicable_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of hosts on which the errata is applicable.",
+				Transform:   transform.FromField("HostsApplicableCount"),
+			},
+			{
+				Name:        "packages",
+				Type:        proto.ColumnType_JSON,
+				Description: "The packages related to the errata.",
+				Transform:   transform.FromField("Packages"),
+			},
+			{
+				Name:        "cves",
+				Type:        proto.ColumnType_JSON,
+				Description: "The CVEs fixed by the errata.",
+				Transform:   transform.FromField("CVEs"),
+			},
+			{
+				Name:        "bugs",
+				Type:        proto.ColumnType_JSON,
+				Description: "The bugs fixed by the errata.",
+				Transform:   transform.FromField("Bugs"),
+			},
+			{
+				Name:        "module_streams",
+				Type:        proto.ColumnType_JSON,
+				Description: "The module streams related to the errata.",
+				Transform:   transform.FromField("ModuleStreams"),
+			},
+			// filter columns
+			{
+				Name:        "content_view_version_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the content view version the errata were listed from; it is only set when the query filters on it.",
+				Transform:   transform.FromField("ContentViewVersionID"),
+			},
+			{
+				Name:        "repository_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the repository the errata were listed from; it is only set when the query filters on it.",
+				Transform:   transform.FromField("RepositoryID"),
+			},
+			{
+				Name:        "environment_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the lifecycle environment the errata were listed from; it is only set when the query filters on it.",
+				Transform:   transform.FromField("EnvironmentID"),
+			},
+			// join columns
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the errata were listed from.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the errata were listed from.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteErrata,
+			KeyColumns: append(append(errataCatalogSearchColumns.KeyColumns(),
+				&plugin.KeyColumn{
+					Name:    "content_view_version_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "repository_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "environment_id",
+					Require: plugin.Optional,
+				},
+			), organizationKeyColumns()...),
+		},
+	}
+}
+
+// errataCatalogSearchColumns lists the columns whose quals are pushed down
+// into the scoped search of /katello/api/errata; the content view version,
+// repository and lifecycle environment are sent as parameters instead.
+var errataCatalogSearchColumns = searchColumns{
+	{Column: "type", Field: "type", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "severity", Field: "severity", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "errata_id", Field: "errata_id", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "issued_at", Field: "issued", Kind: searchTime, Operators: []string{"=", "<", "<=", ">", ">="}},
+	{Column: "updated_at", Field: "updated", Kind: searchTime, Operators: []string{"=", "<", "<=", ">", ">="}},
+}
+
+// errataScope is a combination of content view version, repository and
+// lifecycle environment the errata catalog is listed by; zero ids are not
+// sent.
+type errataScope struct {
+	ContentViewVersionID int
+	RepositoryID         int
+	EnvironmentID        int
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteErrata(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite errata catalog", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	search := errataCatalogSearchColumns.Search(d.Quals)
+	plugin.Logger(ctx).Debug("pushing quals down into scoped search", "search", search)
+
+	err = listSatelliteErrataImpl(ctx, d, client, search, errataScopesOf(d), func(errata *satelliteErrata) bool {
+		d.StreamListItem(ctx, errata)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving errata", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// errataScopesOf returns the combinations of content view version, repository
+// and lifecycle environment in the quals, if any, that the errata catalog must
+// be listed by, one at a time.
+func errataScopesOf(d *plugin.QueryData) []errataScope {
+	ids := func(column string) []int {
+		quals, ok := d.EqualsQuals[column]
+		if !ok {
+			return []int{0}
+		}
+		result := []int{}
+		for _, value := range qualValues(quals) {
+			result = append(result, int(value.GetInt64Value()))
+		}
+		return result
+	}
+
+	scopes := []errataScope{}
+	for _, contentViewVersionID := range ids("content_view_version_id") {
+		for _, repositoryID := range ids("repository_id") {
+			for _, environmentID := range ids("environment_id") {
+				scopes = append(scopes, errataScope{
+					ContentViewVersionID: contentViewVersionID,
+					RepositoryID:         repositoryID,
+					EnvironmentID:        environmentID,
+				})
+			}
+		}
+	}
+	return scopes
+}
+
+// listSatelliteErrataImpl streams the errata in the catalog matching the
+// given scoped search, one organization and one scope at a time.
+func listSatelliteErrataImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, search string, scopes []errataScope, stream func(*satelliteErrata) bool) error {
+	organizations, scoped, err := getOrganizations(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving organizations", "error", err)
+		return err
+	}
+	if !scoped {
+		organizations = []apiTaxonomy{{}}
+	}
+
+	for _, organization := range organizations {
+		for _, scope := range scopes {
+			more := true
+			err := paginate(ctx, client, GetPerPage(d.Connection), "/katello/api/errata", func(request *resty.Request) {
+				params := map[string]int{
+					organizationTaxonomy.Param: organization.ID,
+					"content_view_version_id":  scope.ContentViewVersionID,
+					"repository_id":            scope.RepositoryID,
+					"environment_id":           scope.EnvironmentID,
+				}
+				for param, id := range params {
+					if id != 0 {
+						request.SetQueryParam(param, fmt.Sprintf("%d", id))
+					}
+				}
+				if search != "" {
+					request.SetQueryParam("search", search)
+				}
+			}, func(errata apiErrata) bool {
+				more = stream(&satelliteErrata{
+					OrganizationID:   organization.ID,
+					OrganizationName: organization.Name,
+					errataScope:      scope,
+					apiErrata:        errata,
+				})
+				return more
+			})
+			if err != nil {
+				return err
+			}
+			if !more || ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// satelliteErrata is an erratum in the catalog as streamed to the table, along
+// with the organization and scope it was listed by.
+type satelliteErrata struct {
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	errataScope
+	apiErrata
+}
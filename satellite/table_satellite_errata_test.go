@@ -0,0 +1,120 @@
+package satellite
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func intQual(v int64) *proto.QualValue {
+	return &proto.QualValue{Value: &proto.QualValue_Int64Value{Int64Value: v}}
+}
+
+func TestErrataScopesOf(t *testing.T) {
+	scopes := errataScopesOf(&plugin.QueryData{})
+	if !reflect.DeepEqual(scopes, []errataScope{{}}) {
+		t.Fatalf("error: expected a single empty scope, got %v", scopes)
+	}
+
+	d := &plugin.QueryData{
+		EqualsQuals: map[string]*proto.QualValue{
+			"content_view_version_id": intQual(7),
+			"repository_id": {Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{
+				Values: []*proto.QualValue{intQual(1), intQual(2)},
+			}}},
+		},
+	}
+	expected := []errataScope{
+		{ContentViewVersionID: 7, RepositoryID: 1},
+		{ContentViewVersionID: 7, RepositoryID: 2},
+	}
+	if scopes := errataScopesOf(d); !reflect.DeepEqual(scopes, expected) {
+		t.Fatalf("error: expected %v, got %v", expected, scopes)
+	}
+}
+
+func TestListSatelliteErrata(t *testing.T) {
+	tests := []struct {
+		name     string
+		quals    map[string]*proto.QualValue
+		expected []string
+	}{
+		{
+			name:  "unscoped",
+			quals: map[string]*proto.QualValue{},
+			expected: []string{
+				"organization_id=1&page=1&per_page=100&search=type+%3D+%22security%22",
+				"organization_id=2&page=1&per_page=100&search=type+%3D+%22security%22",
+			},
+		},
+		{
+			name: "content view version and repositories",
+			quals: map[string]*proto.QualValue{
+				"content_view_version_id": intQual(7),
+				"repository_id": {Value: &proto.QualValue_ListValue{ListValue: &proto.QualValueList{
+					Values: []*proto.QualValue{intQual(3), intQual(4)},
+				}}},
+			},
+			expected: []string{
+				"content_view_version_id=7&organization_id=1&page=1&per_page=100&repository_id=3&search=type+%3D+%22security%22",
+				"content_view_version_id=7&organization_id=1&page=1&per_page=100&repository_id=4&search=type+%3D+%22security%22",
+				"content_view_version_id=7&organization_id=2&page=1&per_page=100&repository_id=3&search=type+%3D+%22security%22",
+				"content_view_version_id=7&organization_id=2&page=1&per_page=100&repository_id=4&search=type+%3D+%22security%22",
+			},
+		},
+		{
+			name: "environment in one organization",
+			quals: map[string]*proto.QualValue{
+				"environment_id":  intQual(5),
+				"organization_id": intQual(2),
+			},
+			expected: []string{
+				"environment_id=5&organization_id=2&page=1&per_page=100&search=type+%3D+%22security%22",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		requests := []string{}
+		client := newTestClient(t, map[string]string{
+			"/api/organizations":  `{"total":2,"subtotal":2,"page":1,"per_page":100,"results":[{"id":1,"name":"ACME"},{"id":2,"name":"Umbrella"}]}`,
+			"/katello/api/errata": `{"total":1,"subtotal":1,"page":1,"per_page":100,"results":[{"id":1,"errata_id":"RHSA-2023:0001","type":"security"}]}`,
+		}, func(r *http.Request) bool {
+			if r.URL.Path == "/katello/api/errata" {
+				requests = append(requests, r.URL.RawQuery)
+			}
+			return true
+		})
+		d := newTestQueryData(t, satelliteConfig{
+			Endpoint:          utils.PointerTo("https://satellite.example.com"),
+			Username:          utils.PointerTo("admin"),
+			Password:          utils.PointerTo("secret"),
+			Organizations:     []string{AllOrganizations},
+			CredentialsSource: utils.PointerTo(CredentialsSourceConfig),
+		}, test.quals)
+
+		organizations := []string{}
+		err := listSatelliteErrataImpl(testContext(), d, client, `type = "security"`, errataScopesOf(d), func(errata *satelliteErrata) bool {
+			if errata.ErrataID != "RHSA-2023:0001" {
+				t.Fatalf("error: %s: unexpected errata %v", test.name, errata)
+			}
+			organizations = append(organizations, errata.OrganizationName)
+			return true
+		})
+		if err != nil {
+			t.Fatalf("error: %s: %v", test.name, err)
+		}
+		sort.Strings(requests)
+		if !reflect.DeepEqual(requests, test.expected) {
+			t.Fatalf("error: %s: expected requests %v, got %v", test.name, test.expected, requests)
+		}
+		if len(organizations) != len(test.expected) || organizations[len(organizations)-1] != "Umbrella" {
+			t.Fatalf("error: %s: unexpected organizations %v", test.name, organizations)
+		}
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/dihedron/steampipe-plugin-utils/utils"
 	"github.com/go-resty/resty/v2"
@@ -36,7 +36,19 @@ func tableSatelliteHost(_ context.Context) *plugin.Table {
 			{
 				Name:        "organization",
 				Type:        proto.ColumnType_STRING,
-				Description: "The organisation managing the host.",
+				Description: "Deprecated: use organization_name, which has the same value and is the column all the host tables share.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organisation managing the host.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organisation managing the host.",
 				Transform:   transform.FromField("OrganizationName"),
 			},
 			{
@@ -131,21 +143,21 @@ func tableSatelliteHost(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "created_at",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_TIMESTAMP,
 				Description: "The machine's creation time.",
-				Transform:   transform.FromField("CreatedAt").Transform(ToTime),
+				Transform:   transform.FromField("CreatedAt").Transform(ToTimestamp),
 			},
 			{
 				Name:        "updated_at",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_TIMESTAMP,
 				Description: "The machine's update time.",
-				Transform:   transform.FromField("UpdatedAt").Transform(ToTime),
+				Transform:   transform.FromField("UpdatedAt").Transform(ToTimestamp),
 			},
 			{
 				Name:        "installed_at",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_TIMESTAMP,
 				Description: "The machine's installation time.",
-				Transform:   transform.FromField("InstalledAt").Transform(ToTime),
+				Transform:   transform.FromField("InstalledAt").Transform(ToTimestamp),
 			},
 			{
 				Name:        "enabled",
@@ -155,7 +167,7 @@ func tableSatelliteHost(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "managed",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_BOOL,
 				Description: "Whether the machine is managed.",
 				Transform:   transform.FromField("Managed"),
 			},
@@ -195,28 +207,234 @@ func tableSatelliteHost(_ context.Context) *plugin.Table {
 				Description: "The machine subscription status.",
 				Transform:   transform.FromField("SubscriptionStatusLabel"),
 			},
+			// content facet columns, null if the machine is not registered to Katello
+			{
+				Name:        "content_view_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the content view the machine is subscribed to.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentViewID").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "content_view_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the content view the machine is subscribed to.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentViewName").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "content_view_version",
+				Type:        proto.ColumnType_STRING,
+				Description: "The version of the content view the machine is subscribed to.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentViewVersion").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "content_view_default",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the machine is subscribed to the default content view.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentViewDefault").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "lifecycle_environment_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the machine's lifecycle environment.",
+				Transform:   transform.FromField("ContentFacetAttributes.LifecycleEnvironmentID").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "lifecycle_environment_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the machine's lifecycle environment.",
+				Transform:   transform.FromField("ContentFacetAttributes.LifecycleEnvironmentName").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "lifecycle_environment_library",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the machine's lifecycle environment is the library.",
+				Transform:   transform.FromField("ContentFacetAttributes.LifecycleEnvironmentLibrary").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "content_source_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the capsule the machine gets its content from.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentSourceID").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "content_source_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the capsule the machine gets its content from.",
+				Transform:   transform.FromField("ContentFacetAttributes.ContentSourceName").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "kickstart_repository_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the machine's kickstart repository.",
+				Transform:   transform.FromField("ContentFacetAttributes.KickstartRepositoryName").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "security_errata_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of security errata applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ErrataCounts.Security").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "bugfix_errata_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of bug fix errata applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ErrataCounts.Bugfix").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "enhancement_errata_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of enhancement errata applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ErrataCounts.Enhancement").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "total_errata_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The total number of errata applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ErrataCounts.Total").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "applicable_package_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of package updates applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ApplicablePackageCount").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "upgradable_package_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of package updates installable on the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.UpgradablePackageCount").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "applicable_module_stream_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of module stream updates applicable to the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.ApplicableModuleStreamCount").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "upgradable_module_stream_count",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of module stream updates installable on the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.UpgradableModuleStreamCount").Transform(nullWithoutContentFacet),
+			},
+			{
+				Name:        "tracer_installed",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether katello-host-tools-tracer is installed on the machine.",
+				Transform:   transform.FromField("ContentFacetAttributes.KatelloTracerInstalled").Transform(nullWithoutContentFacet),
+			},
+			// subscription facet columns, null if the machine is not registered
+			{
+				Name:        "subscription_uuid",
+				Type:        proto.ColumnType_STRING,
+				Description: "The UUID the machine is registered with.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.UUID").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "last_checkin",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The last time the machine checked in with Satellite.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.LastCheckin").Transform(ToTimestamp).Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "checkin_age_seconds",
+				Type:        proto.ColumnType_INT,
+				Description: "The number of seconds since the machine last checked in with Satellite.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.LastCheckin").Transform(ToAgeSeconds).Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "registered_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The time when the machine was registered.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.RegisteredAt").Transform(ToTimestamp).Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "registered_through",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Satellite or capsule the machine was registered through.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.RegisteredThrough").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "service_level",
+				Type:        proto.ColumnType_STRING,
+				Description: "The machine's service level agreement.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.ServiceLevel").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "release_version",
+				Type:        proto.ColumnType_STRING,
+				Description: "The release version the machine is pinned to.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.ReleaseVersion").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "hypervisor",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the machine is a hypervisor.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.Hypervisor").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "virtual_host",
+				Type:        proto.ColumnType_JSON,
+				Description: "The hypervisor the machine runs on, if it is a virtual guest.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.VirtualHost").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "purpose_role",
+				Type:        proto.ColumnType_STRING,
+				Description: "The machine's system purpose role.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.PurposeRole").Transform(nullWithoutSubscriptionFacet),
+			},
+			{
+				Name:        "purpose_usage",
+				Type:        proto.ColumnType_STRING,
+				Description: "The machine's system purpose usage.",
+				Transform:   transform.FromField("SubscriptionFacetAttributes.PurposeUsage").Transform(nullWithoutSubscriptionFacet),
+			},
+			// detail columns, only reported by the single host endpoint
 			{
 				Name:        "facts",
 				Type:        proto.ColumnType_JSON,
 				Description: "The machine's facts.",
+				Hydrate:     getSatelliteHostDetails,
 				Transform:   transform.FromField("Facts"),
 			},
+			{
+				Name:        "parameters",
+				Type:        proto.ColumnType_JSON,
+				Description: "The parameters set on the machine.",
+				Hydrate:     getSatelliteHostDetails,
+				Transform:   transform.FromField("Parameters"),
+			},
+			{
+				Name:        "all_parameters",
+				Type:        proto.ColumnType_JSON,
+				Description: "The parameters applying to the machine, including inherited ones.",
+				Hydrate:     getSatelliteHostDetails,
+				Transform:   transform.FromField("AllParameters"),
+			},
+			{
+				Name:        "interfaces",
+				Type:        proto.ColumnType_JSON,
+				Description: "The machine's network interfaces.",
+				Hydrate:     getSatelliteHostDetails,
+				Transform:   transform.FromField("Interfaces"),
+			},
+			{
+				Name:        "host_collections",
+				Type:        proto.ColumnType_JSON,
+				Description: "The host collections the machine belongs to.",
+				Hydrate:     getSatelliteHostDetails,
+				Transform:   transform.FromField("HostCollections"),
+			},
 		},
 		List: &plugin.ListConfig{
-			Hydrate: listSatelliteHost,
-			KeyColumns: plugin.KeyColumnSlice{
-				&plugin.KeyColumn{
-					Name:    "id",
-					Require: plugin.Optional,
-				},
-				&plugin.KeyColumn{
-					Name:    "name",
-					Require: plugin.Optional,
-				},
-			},
+			Hydrate:    listSatelliteHost,
+			KeyColumns: append(hostSearchColumns.KeyColumns(), organizationKeyColumns()...),
 		},
 		Get: &plugin.GetConfig{
 			Hydrate: getSatelliteHost,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
 			KeyColumns: plugin.KeyColumnSlice{
 				&plugin.KeyColumn{
 					Name:    "id",
@@ -227,11 +445,30 @@ func tableSatelliteHost(_ context.Context) *plugin.Table {
 					Require: plugin.AnyOf,
 				},
 			},
-			//			KeyColumns: plugin.SingleColumn("id"),
 		},
 	}
 }
 
+// hostSearchColumns lists the columns whose quals are pushed down into the
+// scoped search of /api/hosts.
+var hostSearchColumns = searchColumns{
+	{Column: "id", Field: "id", Kind: searchInt, Operators: []string{"=", "<>"}},
+	{Column: "name", Field: "name", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "operating_system", Field: "os_title", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "architecture", Field: "architecture", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "environment", Field: "environment", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "host_group_name", Field: "hostgroup_name", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "host_group_title", Field: "hostgroup_title", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "errata_status", Field: "errata_status", Kind: searchString, Operators: []string{"=", "<>"}, Values: map[string]string{
+		"all errata applied":             "updated",
+		"non-security errata applicable": "errata_needed",
+		"security errata applicable":     "security_needed",
+		"could not calculate errata status, ensure host is registered and katello-host-tools is installed": "unknown",
+	}},
+	{Column: "managed", Field: "managed", Kind: searchBool, Operators: []string{"=", "<>"}},
+	{Column: "created_at", Field: "created_at", Kind: searchTime, Operators: []string{"=", "<", "<=", ">", ">="}},
+}
+
 //// LIST FUNCTIONS
 
 func listSatelliteHost(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -244,102 +481,86 @@ func listSatelliteHost(ctx context.Context, d *plugin.QueryData, h *plugin.Hydra
 		return nil, err
 	}
 
-	hosts, err := listSatelliteHostImpl(ctx, client, false)
+	search := hostSearchColumns.Search(d.Quals)
+	plugin.Logger(ctx).Debug("pushing quals down into scoped search", "search", search)
+
+	err = listSatelliteHostImpl(ctx, d, client, false, search, func(host apiHost) bool {
+		d.StreamListItem(ctx, &host)
+		return d.RowsRemaining(ctx) != 0
+	})
 	if err != nil {
 		plugin.Logger(ctx).Error("error retrieving list of hosts", "error", err)
 		return nil, err
 	}
 
-	for _, host := range hosts {
-		host := host
-		if ctx.Err() != nil {
-			plugin.Logger(ctx).Debug("context done, exit")
-			break
-		}
-		d.StreamListItem(ctx, &host)
-	}
-
 	return nil, nil
 }
 
-func listSatelliteHostImpl(ctx context.Context, client *resty.Client, thin bool) ([]apiHost, error) {
+// listSatelliteHostImpl streams the hosts matching the given scoped search,
+// one organization at a time; if thin is set, only the host IDs and names are
+// retrieved, along with the organization they belong to if it is needed.
+func listSatelliteHostImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, thin bool, search string, stream func(apiHost) bool) error {
 	plugin.Logger(ctx).Debug("retrieving satellite host list")
 
-	hosts := []apiHost{}
-
-	page := 1
-loop:
-	for {
-		request := client.
-			R().
-			SetContext(ctx).
-			SetQueryParam("page", fmt.Sprintf("%d", page))
-
-		if thin {
-			request.SetQueryParam("thin", "true")
-		}
+	organizations, scoped, err := getOrganizations(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving organizations", "error", err)
+		return err
+	}
+	if !scoped {
+		// thin listings do not report the organization of the hosts
+		thin = thin && !needsOrganization(d)
+	}
+	return listHosts(ctx, d, client, organizations, scoped, thin, search, stream)
+}
 
-		result := &struct {
-			Total    int         `json:"total"`
-			Subtotal int         `json:"subtotal"`
-			Page     interface{} `json:"page"`
-			PerPage  int         `json:"per_page"`
-			Search   string      `json:"search"`
-			Sort     struct {
-				By    string `json:"by"`
-				Order string `json:"order"`
-			} `json:"sort"`
-			Hosts []apiHost `json:"results"`
-		}{}
-		request.SetResult(result)
-
-		response, err := request.Get("/api/hosts")
-		if err != nil {
-			plugin.Logger(ctx).Error("error performing request", "error", err, "response", utils.ToJSON(response.Body()))
-			return nil, err
-		}
-		plugin.Logger(ctx).Debug("request successful", "total", result.Total, "subtotal", result.Subtotal, "page", result.Page, "per page", result.PerPage, "response", utils.ToJSON(response.Body()))
+// listHosts streams the hosts in the given organizations, or all the visible
+// hosts if the listing is not scoped.
+func listHosts(ctx context.Context, d *plugin.QueryData, client *resty.Client, organizations []apiTaxonomy, scoped bool, thin bool, search string, stream func(apiHost) bool) error {
+	if !scoped {
+		organizations = []apiTaxonomy{{}}
+	}
 
-		for _, host := range result.Hosts {
-			host := host
-			if ctx.Err() != nil {
-				plugin.Logger(ctx).Debug("context done, exit")
-				break loop
+	for _, organization := range organizations {
+		more := true
+		err := paginate(ctx, client, GetPerPage(d.Connection), "/api/hosts", func(request *resty.Request) {
+			if organization.ID != 0 {
+				request.SetQueryParam(organizationTaxonomy.Param, strconv.Itoa(organization.ID))
 			}
-			hosts = append(hosts, host)
-		}
-
-		// handle pagination. Note that the Satellite API returns results.Page as an
-		// integer if there is no page?{page} query  parameter, and as a string if you
-		// set one; thus we need to handle both cases
-		resultPage := 0
-		switch v := result.Page.(type) {
-		case int:
-			resultPage = v
-		case int32:
-			resultPage = int(v)
-		case int64:
-			resultPage = int(v)
-		case float32:
-			resultPage = int(v)
-		case float64:
-			resultPage = int(v)
-		case string:
-			resultPage, _ = strconv.Atoi(v)
-		default:
-			plugin.Logger(ctx).Debug("unsupported type in pagination", "type", fmt.Sprintf("%T", result.Page))
-			return nil, fmt.Errorf("unexpected type in pagination API result: %T", result.Page)
+			if thin {
+				request.SetQueryParam("thin", "true")
+			}
+			if search != "" {
+				request.SetQueryParam("search", search)
+			}
+		}, func(host apiHost) bool {
+			if organization.ID != 0 {
+				host.OrganizationID = organization.ID
+				host.OrganizationName = organization.Name
+			}
+			more = stream(host)
+			return more
+		})
+		if err != nil {
+			return err
 		}
-		if result.PerPage*resultPage < result.Total {
-			page++
-			plugin.Logger(ctx).Debug("retrieving next page", "page", page)
-		} else {
-			plugin.Logger(ctx).Debug("all pages retrieved", "subtotal", result.Subtotal, "total", result.Total)
+		if !more || ctx.Err() != nil {
 			break
 		}
 	}
+	return nil
+}
 
-	return hosts, nil
+// lookupHost retrieves the ID, name and organization of a host known by its
+// ID, if it is visible in the organizations in scope.
+func lookupHost(ctx context.Context, d *plugin.QueryData, client *resty.Client, id int) (apiHost, bool, error) {
+	found := apiHost{}
+	ok := false
+	err := listSatelliteHostImpl(ctx, d, client, true, fmt.Sprintf("id = %d", id), func(host apiHost) bool {
+		found, ok = host, true
+		return false
+	})
+	return found, ok, err
 }
 
 //// HYDRATE FUNCTIONS
@@ -347,39 +568,80 @@ loop:
 func getSatelliteHost(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
 	setLogLevel(ctx, d)
 
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
 	id := ""
 	if val, ok := d.EqualsQuals["id"]; ok {
-		plugin.Logger(ctx).Debug("retrieving satellite host by id", "id", id)
 		id = fmt.Sprintf("%d", val.GetInt64Value())
+		plugin.Logger(ctx).Debug("retrieving satellite host by id", "id", id)
 	} else if val, ok := d.EqualsQuals["name"]; ok {
-		plugin.Logger(ctx).Debug("retrieving satellite host by name", "name", id)
-		id = val.GetStringValue()
+		plugin.Logger(ctx).Debug("retrieving satellite host by name", "name", val.GetStringValue())
+		host, ok, err := lookupHostName(ctx, d, client, val.GetStringValue())
+		if err != nil {
+			plugin.Logger(ctx).Error("error retrieving host index", "error", err)
+			return nil, err
+		}
+		if !ok {
+			plugin.Logger(ctx).Debug("unknown host name", "name", val.GetStringValue())
+			return nil, fmt.Errorf("unknown host name %s: %w", val.GetStringValue(), ErrNotFound)
+		}
+		id = fmt.Sprintf("%d", host.ID)
 	} else {
 		plugin.Logger(ctx).Error("no valid key provided")
 		return nil, errors.New("no valid key provided")
 	}
 
+	return getSatelliteHostImpl(ctx, client, id)
+}
+
+// getSatelliteHostDetails hydrates the columns that /api/hosts does not
+// report; it is only called when the query selects one of them, and the SDK
+// shares its result among all of them, so each host is retrieved at most
+// once. Hosts retrieved via Get already have their details.
+func getSatelliteHostDetails(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	id := 0
+	switch item := h.Item.(type) {
+	case *hostDetail:
+		plugin.Logger(ctx).Debug("host details already available", "id", item.ID)
+		return item, nil
+	case *apiHost:
+		id = item.ID
+	default:
+		return nil, fmt.Errorf("unexpected host type %T", h.Item)
+	}
+
 	client, err := getClient(ctx, d)
 	if err != nil {
 		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
 		return nil, err
 	}
+	return getSatelliteHostImpl(ctx, client, fmt.Sprintf("%d", id))
+}
 
+// getSatelliteHostImpl retrieves a host, along with its details.
+func getSatelliteHostImpl(ctx context.Context, client *resty.Client, id string) (*hostDetail, error) {
 	request := client.
 		R().
 		SetContext(ctx)
 
 	request = request.SetPathParam("id", id)
 
-	host := &apiHost{}
+	host := &hostDetail{}
 	request.SetResult(host)
 	response, err := request.Get("/api/hosts/{id}")
 	if err != nil || response.IsError() {
 		plugin.Logger(ctx).Error("error performing request", "url", response.Request.URL, "status", response.Status, "error", err)
 		if err != nil {
-			err = fmt.Errorf("error retrieving host %q via %q (status %d - %s, error %w)", id, response.Request.URL, response.StatusCode(), response.Status(), err)
+			err = fmt.Errorf("error retrieving host %q via %q (status %d - %s, error %w)", id, response.Request.URL, response.StatusCode(), response.Status(), certificateError(err))
 		} else {
-			err = fmt.Errorf("error resolving host %q via %q (status %d - %s)", id, response.Request.URL, response.StatusCode(), response.Status())
+			err = fmt.Errorf("error retrieving host %q via %q (status %d - %s)", id, response.Request.URL, response.StatusCode(), response.Status())
+			if response.StatusCode() == http.StatusNotFound {
+				err = fmt.Errorf("%v: %w", err, ErrNotFound)
+			}
 		}
 		return nil, err
 	}
@@ -388,53 +650,45 @@ func getSatelliteHost(ctx context.Context, d *plugin.QueryData, h *plugin.Hydrat
 	return host, nil
 }
 
-const SatelliteHostIDPrefix = "satellite_hostname::"
-
-func resolveHostID(ctx context.Context, d *plugin.QueryData, name string) (int, error) {
-	plugin.Logger(ctx).Debug("resolving host id by name", "name", name)
-
-	key := SatelliteHostIDPrefix + strings.ToLower(name)
-
-	// load id from cache, if available
-	if cachedData, ok := d.ConnectionManager.Cache.Get(key); ok {
-		plugin.Logger(ctx).Debug("returning satellite id from cache", key, cachedData.(int))
-		return cachedData.(int), nil
+// hostOf returns the host in a row, whether it was listed or retrieved via
+// Get.
+func hostOf(item interface{}) *apiHost {
+	switch host := item.(type) {
+	case *apiHost:
+		return host
+	case *hostDetail:
+		return &host.apiHost
 	}
+	return nil
+}
 
-	// acquire REST API client
-	client, err := getClient(ctx, d)
-	if err != nil {
-		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
-		return 0, err
+// nullWithoutContentFacet returns null for machines that have no content
+// facet, so that their counts and flags are not reported as zero or false.
+func nullWithoutContentFacet(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	host := hostOf(d.HydrateItem)
+	if host == nil || (host.ContentFacetAttributes.ID == 0 && host.ContentFacetAttributes.UUID == "") {
+		return nil, nil
 	}
+	return d.Value, nil
+}
 
-	request := client.
-		R().
-		SetContext(ctx).
-		SetPathParam("id", name).
-		SetQueryParam("thin", "true")
-
-	host := &apiHost{}
-	request.SetResult(host)
-	response, err := request.Get("/api/hosts/{id}")
-	if err != nil || response.IsError() {
-		plugin.Logger(ctx).Error("error performing request", "url", response.Request.URL, "status", response.Status, "error", err)
-		if err != nil {
-			err = fmt.Errorf("error resolving host id from name %q (status %d - %s, error %w)", response.Request.URL, response.StatusCode(), response.Status(), err)
-		} else {
-			err = fmt.Errorf("error resolving host id from name %q (status %d - %s)", response.Request.URL, response.StatusCode(), response.Status())
-		}
-		return 0, err
+// nullWithoutSubscriptionFacet returns null for machines that have no
+// subscription facet, i.e. that are not registered.
+func nullWithoutSubscriptionFacet(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	host := hostOf(d.HydrateItem)
+	if host == nil || (host.SubscriptionFacetAttributes.ID == 0 && host.SubscriptionFacetAttributes.UUID == "") {
+		return nil, nil
 	}
-	plugin.Logger(ctx).Debug("request successful", "name", strings.ToLower(name), "id", host.ID)
-
-	// save to cache
-	plugin.Logger(ctx).Debug("saving satellite id to cache", key, host.ID)
-	d.ConnectionManager.Cache.Set(key, client)
+	return d.Value, nil
+}
 
-	return host.ID, nil
+// hostDetail is a host as reported by /api/hosts/{id}.
+type hostDetail struct {
+	apiHost
+	apiHostDetails
 }
 
+// apiHost is a host as reported by /api/hosts.
 type apiHost struct {
 	IPv4                     string      `json:"ip,omitempty" yaml:"ip,omitempty"`
 	IPv6                     string      `json:"ip6,omitempty" yaml:"ip6,omitempty"`
@@ -525,25 +779,7 @@ type apiHost struct {
 	HostGroupID              int         `json:"hostgroup_id,omitempty" yaml:"hostgroup_id,omitempty"`
 	HostGroupName            string      `json:"hostgroup_name,omitempty" yaml:"hostgroup_name,omitempty"`
 	HostGroupTitle           string      `json:"hostgroup_title,omitempty" yaml:"hostgroup_title,omitempty"`
-	Parameters               []struct {
-		Priority      int         `json:"priority,omitempty" yaml:"priority,omitempty"`
-		CreatedAt     string      `json:"created_at,omitempty" yaml:"created_at,omitempty"`
-		UpdatedAt     string      `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
-		ID            int         `json:"id,omitempty" yaml:"id,omitempty"`
-		Name          string      `json:"name,omitempty" yaml:"name,omitempty"`
-		ParameterType string      `json:"parameter_type" yaml:"parameter_type"`
-		Value         interface{} `json:"value,omitempty" yaml:"value,omitempty"`
-	} `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	AllParameters []struct {
-		Priority      int         `json:"priority,omitempty" yaml:"priority,omitempty"`
-		CreatedAt     string      `json:"created_at,omitempty" yaml:"created_at,omitempty"`
-		UpdatedAt     string      `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
-		ID            int         `json:"id,omitempty" yaml:"id,omitempty"`
-		Name          string      `json:"name,omitempty" yaml:"name,omitempty"`
-		ParameterType string      `json:"parameter_type" yaml:"parameter_type"`
-		Value         interface{} `json:"value,omitempty" yaml:"value,omitempty"`
-	} `json:"all_parameters,omitempty" yaml:"all_parameters,omitempty"`
-	ContentFacetAttributes struct {
+	ContentFacetAttributes   struct {
 		ID                       int    `json:"id,omitempty" yaml:"id,omitempty"`
 		UUID                     string `json:"uuid,omitempty" yaml:"uuid,omitempty"`
 		ContentViewID            int    `json:"content_view_id,omitempty" yaml:"content_view_id,omitempty"`
@@ -591,7 +827,7 @@ type apiHost struct {
 		DmiUUID           string        `json:"dmi_uuid,omitempty" yaml:"dmi_uuid,omitempty"`
 		ID                int           `json:"id,omitempty" yaml:"id,omitempty"`
 		UUID              string        `json:"uuid,omitempty" yaml:"uuid,omitempty"`
-		LastCheckin       string        `json:"last_checkin,omitempty" yaml:"last_checkin,omitempty"`
+		LastCheckin       *Time         `json:"last_checkin,omitempty" yaml:"last_checkin,omitempty"`
 		ServiceLevel      string        `json:"service_level,omitempty" yaml:"service_level,omitempty"`
 		ReleaseVersion    interface{}   `json:"release_version,omitempty" yaml:"release_version,omitempty"`
 		Autoheal          bool          `json:"autoheal,omitempty" yaml:"autoheal,omitempty"`
@@ -619,38 +855,20 @@ type apiHost struct {
 		} `json:"activation_keys,omitempty" yaml:"activation_keys,omitempty"`
 		ComplianceReasons []interface{} `json:"compliance_reasons,omitempty" yaml:"compliance_reasons,omitempty"`
 	} `json:"subscription_facet_attributes,omitempty" yaml:"subscription_facet_attributes,omitempty"`
-	HostCollections []interface{} `json:"host_collections,omitempty" yaml:"host_collections,omitempty"`
-	Interfaces      []struct {
-		SubnetID        int    `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
-		SubnetName      string `json:"subnet_name,omitempty" yaml:"subnet_name,omitempty"`
-		Subnet6ID       int    `json:"subnet6_id,omitempty" yaml:"subnet6_id,omitempty"`
-		Subnet6Name     string `json:"subnet6_name,omitempty" yaml:"subnet6_name,omitempty"`
-		DomainID        int    `json:"domain_id,omitempty" yaml:"domain_id,omitempty"`
-		DomainName      string `json:"domain_name,omitempty" yaml:"domain_name,omitempty"`
-		CreatedAt       string `json:"created_at,omitempty" yaml:"created_at,omitempty"`
-		UpdatedAt       string `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
-		Managed         bool   `json:"managed,omitempty" yaml:"managed,omitempty"`
-		Identifier      string `json:"identifier,omitempty" yaml:"identifier,omitempty"`
-		ID              int    `json:"id,omitempty" yaml:"id,omitempty"`
-		Name            string `json:"name,omitempty" yaml:"name,omitempty"`
-		IPv4            string `json:"ip,omitempty" yaml:"ip,omitempty"`
-		IPv6            string `json:"ip6,omitempty" yaml:"ip6,omitempty"`
-		MAC             string `json:"mac,omitempty" yaml:"mac,omitempty"`
-		MTU             int    `json:"mtu,omitempty" yaml:"mtu,omitempty"`
-		FQDN            string `json:"fqdn,omitempty" yaml:"fqdn,omitempty"`
-		Primary         bool   `json:"primary,omitempty" yaml:"primary,omitempty"`
-		Provision       bool   `json:"provision,omitempty" yaml:"provision,omitempty"`
-		Type            string `json:"type,omitempty" yaml:"type,omitempty"`
-		Execution       bool   `json:"execution,omitempty" yaml:"execution,omitempty"`
-		Mode            string `json:"mode,omitempty" yaml:"mode,omitempty"`
-		AttachedDevices string `json:"attached_devices,omitempty" yaml:"attached_devices,omitempty"`
-		BondOptions     string `json:"bond_options,omitempty" yaml:"bond_options,omitempty"`
-		Virtual         bool   `json:"virtual,omitempty" yaml:"virtual,omitempty"`
-		Tag             string `json:"tag,omitempty" yaml:"tag,omitempty"`
-		AttachedTo      string `json:"attached_to,omitempty" yaml:"attached_to,omitempty"`
-	} `json:"interfaces,omitempty"`
-	Facts       map[string]string `json:"facts,omitempty" yaml:"facts,omitempty"`
-	Permissions struct {
+	ConfigurationStatus      int    `json:"configuration_status,omitempty" yaml:"configuration_status,omitempty"`
+	ConfigurationStatusLabel string `json:"configuration_status_label,omitempty" yaml:"configuration_status_label,omitempty"`
+	BuildStatus              int    `json:"build_status,omitempty" yaml:"build_status,omitempty"`
+	BuildStatusLabel         string `json:"build_status_label,omitempty" yaml:"build_status_label,omitempty"` // test
+}
+
+// apiHostDetails are the fields of a host that only /api/hosts/{id} reports.
+type apiHostDetails struct {
+	Parameters      []apiParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	AllParameters   []apiParameter `json:"all_parameters,omitempty" yaml:"all_parameters,omitempty"`
+	HostCollections []interface{}  `json:"host_collections,omitempty" yaml:"host_collections,omitempty"`
+	Interfaces      []apiInterface `json:"interfaces,omitempty"`
+	Facts           facts          `json:"facts,omitempty" yaml:"facts,omitempty"`
+	Permissions     struct {
 		CockpitHosts                 bool `json:"cockpit_hosts,omitempty" yaml:"cockpit_hosts,omitempty"`
 		ViewHosts                    bool `json:"view_hosts,omitempty"  yaml:"view_hosts,omitempty"`
 		CreateHosts                  bool `json:"create_hosts,omitempty" yaml:"create_hosts,omitempty"`
@@ -669,11 +887,7 @@ type apiHost struct {
 		PlayRolesOnHost              bool `json:"play_roles_on_host,omitempty" yaml:"play_roles_on_host,omitempty"`
 		ForgetStatusHosts            bool `json:"forget_status_hosts,omitempty" yaml:"forget_status_hosts,omitempty"`
 	} `json:"permissions,omitempty" yaml:"permissions,omitempty"`
-	PuppetClasses            []interface{} `json:"puppetclasses,omitempty" yaml:"puppetclasses,omitempty"`
-	ConfigGroups             []interface{} `json:"config_groups,omitempty" yaml:"config_groups,omitempty"`
-	AllPuppetClasses         []interface{} `json:"all_puppetclasses,omitempty" yaml:"all_puppetclasses,omitempty"`
-	ConfigurationStatus      int           `json:"configuration_status,omitempty" yaml:"configuration_status,omitempty"`
-	ConfigurationStatusLabel string        `json:"configuration_status_label,omitempty" yaml:"configuration_status_label,omitempty"`
-	BuildStatus              int           `json:"build_status,omitempty" yaml:"build_status,omitempty"`
-	BuildStatusLabel         string        `json:"build_status_label,omitempty" yaml:"build_status_label,omitempty"` // test
+	PuppetClasses    []interface{} `json:"puppetclasses,omitempty" yaml:"puppetclasses,omitempty"`
+	ConfigGroups     []interface{} `json:"config_groups,omitempty" yaml:"config_groups,omitempty"`
+	AllPuppetClasses []interface{} `json:"all_puppetclasses,omitempty" yaml:"all_puppetclasses,omitempty"`
 }
@@ -0,0 +1,177 @@
+package satellite
+
+import (
+	"context"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteHostCVE(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_host_cve",
+		Description: "Red Hat Satellite Host CVEs",
+		Columns: []*plugin.Column{
+			{
+				Name:        "cve_id",
+				Type:        proto.ColumnType_STRING,
+				Description: "The ID of the CVE.",
+				Transform:   transform.FromField("CVEID"),
+			},
+			{
+				Name:        "href",
+				Type:        proto.ColumnType_STRING,
+				Description: "The link to the description of the CVE.",
+				Transform:   transform.FromField("Href"),
+			},
+			{
+				Name:        "errata_id",
+				Type:        proto.ColumnType_STRING,
+				Description: "The ID of the errata fixing the CVE.",
+				Transform:   transform.FromField("ErrataID"),
+			},
+			{
+				Name:        "severity",
+				Type:        proto.ColumnType_STRING,
+				Description: "The severity of the errata fixing the CVE.",
+				Transform:   transform.FromField("Severity"),
+			},
+			{
+				Name:        "issued_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The time when the errata fixing the CVE was issued.",
+				Transform:   transform.FromField("Issued").Transform(ToTimestamp),
+			},
+			{
+				Name:        "installable",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the errata fixing the CVE is installable.",
+				Transform:   transform.FromField("Installable"),
+			},
+			// join columns
+			{
+				Name:        "host_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the host exposed to the CVE.",
+				Transform:   transform.FromField("HostID"),
+			},
+			{
+				Name:        "host_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the host exposed to the CVE. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
+				Transform:   transform.FromField("HostName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteHostCVE,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(plugin.KeyColumnSlice{
+				&plugin.KeyColumn{
+					Name:    "cve_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "host_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "host_name",
+					Require: plugin.Optional,
+				},
+			}, organizationKeyColumns()...),
+		},
+	}
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteHostCVE(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite CVE list for host", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	// the applicable errata are included, since the host is exposed to their
+	// CVEs even if they cannot be installed yet
+	filter := errataFilter{Params: map[string]string{"include_applicable": "true"}}
+	if ids, ok := d.EqualsQuals["cve_id"]; ok {
+		for _, id := range qualValues(ids) {
+			filter.CVEs = append(filter.CVEs, id.GetStringValue())
+		}
+	}
+
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostCVE) bool) error {
+		return listSatelliteHostCVEImpl(ctx, d, client, host, filter, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving CVEs", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// listSatelliteHostCVEImpl streams the CVEs the given host is exposed to, one
+// for each of the errata fixing them; if the filter has any CVEs, only those
+// are streamed.
+func listSatelliteHostCVEImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, host apiHost, filter errataFilter, stream func(*hostCVE) bool) error {
+	return listSatelliteHostErrataImpl(ctx, d, client, host, filter, func(errata *hostErrata) bool {
+		for _, cve := range errata.CVEs {
+			if errata.CVE != "" && cve.ID != errata.CVE {
+				continue
+			}
+			if !stream(&hostCVE{
+				HostID:           errata.HostID,
+				HostName:         errata.HostName,
+				OrganizationID:   errata.OrganizationID,
+				OrganizationName: errata.OrganizationName,
+				CVEID:            cve.ID,
+				Href:             cve.Href,
+				ErrataID:         errata.ErrataID,
+				Severity:         errata.Severity,
+				Issued:           errata.Issued,
+				Installable:      errata.Installable,
+			}) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// hostCVE is a CVE as streamed to the table, along with the host exposed to it
+// and the erratum fixing it.
+type hostCVE struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	CVEID            string `json:"cve_id,omitempty" yaml:"cve_id,omitempty"`
+	Href             string `json:"href,omitempty" yaml:"href,omitempty"`
+	ErrataID         string `json:"errata_id,omitempty" yaml:"errata_id,omitempty"`
+	Severity         string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	Issued           *Time  `json:"issued,omitempty" yaml:"issued,omitempty"`
+	Installable      bool   `json:"installable,omitempty" yaml:"installable,omitempty"`
+}
@@ -0,0 +1,52 @@
+package satellite
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestListSatelliteHostCVE(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/api/hosts/1/errata": `{"total":1,"subtotal":1,"page":1,"per_page":20,"results":[{
+			"id": 1,
+			"errata_id": "RHSA-2023:0001",
+			"severity": "Critical",
+			"issued": "2023-05-01",
+			"installable": false,
+			"cves": [
+				{"cve_id": "CVE-2023-1234", "href": "https://access.redhat.com/security/cve/CVE-2023-1234"},
+				{"cve_id": "CVE-2023-5678", "href": "https://access.redhat.com/security/cve/CVE-2023-5678"}
+			]
+		}]}`,
+	}, func(r *http.Request) bool {
+		return r.URL.Query().Get("include_applicable") == "true"
+	})
+
+	tests := []struct {
+		cves     []string
+		expected []string
+	}{
+		{cves: nil, expected: []string{"CVE-2023-1234", "CVE-2023-5678"}},
+		{cves: []string{"CVE-2023-5678"}, expected: []string{"CVE-2023-5678"}},
+	}
+	for _, test := range tests {
+		filter := errataFilter{Params: map[string]string{"include_applicable": "true"}, CVEs: test.cves}
+		actual := []string{}
+		err := listSatelliteHostCVEImpl(testContext(), &plugin.QueryData{}, client, apiHost{ID: 1, Name: "web01.example.com"}, filter, func(cve *hostCVE) bool {
+			if cve.HostName != "web01.example.com" || cve.ErrataID != "RHSA-2023:0001" || cve.Severity != "Critical" || cve.Href == "" || cve.Issued == nil {
+				t.Fatalf("error: unexpected row %+v", cve)
+			}
+			actual = append(actual, cve.CVEID)
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Fatalf("error: expected %v, got %v", test.expected, actual)
+		}
+	}
+}
@@ -3,9 +3,9 @@ package satellite
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"strings"
 
-	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -44,15 +44,15 @@ func tableSatelliteHostErrata(_ context.Context) *plugin.Table {
 			},
 			{
 				Name:        "issued_at",
-				Type:        proto.ColumnType_STRING,
+				Type:        proto.ColumnType_TIMESTAMP,
 				Description: "The time when the errata was issued.",
-				Transform:   transform.FromField("Issued"),
+				Transform:   transform.FromField("Issued").Transform(ToTimestamp),
 			},
 			{
 				Name:        "updated_at",
-				Type:        proto.ColumnType_STRING,
-				Description: "The time when the errata updated.",
-				Transform:   transform.FromField("Updated"),
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The time when the errata was updated.",
+				Transform:   transform.FromField("Updated").Transform(ToTimestamp),
 			},
 			{
 				Name:        "severity",
@@ -144,6 +144,12 @@ func tableSatelliteHostErrata(_ context.Context) *plugin.Table {
 				Description: "The module streams applicable to this host.",
 				Transform:   transform.FromField("ModuleStreams"),
 			},
+			{
+				Name:        "cve",
+				Type:        proto.ColumnType_STRING,
+				Description: "The CVE the errata were searched by; it is only set when the query filters on it.",
+				Transform:   transform.FromField("CVE"),
+			},
 			// join columns
 			{
 				Name:        "host_id",
@@ -154,13 +160,36 @@ func tableSatelliteHostErrata(_ context.Context) *plugin.Table {
 			{
 				Name:        "host_name",
 				Type:        proto.ColumnType_STRING,
-				Description: "The name of the host having the package.",
+				Description: "The name of the host having the package. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
 				Transform:   transform.FromField("HostName"),
 			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listSatelliteHostErrata,
-			KeyColumns: plugin.KeyColumnSlice{
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(append(errataSearchColumns.KeyColumns(),
+				&plugin.KeyColumn{
+					Name:    "installable",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "cve",
+					Require: plugin.Optional,
+				},
 				&plugin.KeyColumn{
 					Name:    "host_id",
 					Require: plugin.Optional,
@@ -169,11 +198,91 @@ func tableSatelliteHostErrata(_ context.Context) *plugin.Table {
 					Name:    "host_name",
 					Require: plugin.Optional,
 				},
-			},
+			), organizationKeyColumns()...),
 		},
 	}
 }
 
+// errataSearchColumns lists the columns whose quals are pushed down into the
+// scoped search of /api/hosts/{id}/errata; plain equalities on type and
+// severity are sent as the types and severity parameters instead, while
+// installable and cve are handled apart.
+var errataSearchColumns = searchColumns{
+	{Column: "type", Field: "type", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "severity", Field: "severity", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "errata_id", Field: "errata_id", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "issued_at", Field: "issued", Kind: searchTime, Operators: []string{"=", "<", "<=", ">", ">="}},
+}
+
+// errataFilter is what of the quals can be pushed down into the host errata
+// requests.
+type errataFilter struct {
+	// Params are the query parameters to add to the requests.
+	Params map[string]string
+	// Search is the scoped search to add to the requests.
+	Search string
+	// CVEs, if any, are searched one at a time, so that each erratum can be
+	// reported along with the CVE it was found by.
+	CVEs []string
+}
+
+// newErrataFilter translates the given quals into an errataFilter.
+func newErrataFilter(quals plugin.KeyColumnQualMap) errataFilter {
+	filter := errataFilter{Params: map[string]string{}}
+
+	terms := []string{}
+	for _, column := range errataSearchColumns {
+		columnQuals, ok := quals[column.Column]
+		if !ok || columnQuals == nil {
+			continue
+		}
+		for _, qual := range columnQuals.Quals {
+			// a list of types or a single severity can be sent as a parameter,
+			// anything else goes into the scoped search
+			if qual.Operator == "=" {
+				values := []string{}
+				for _, value := range qualValues(qual.Value) {
+					values = append(values, value.GetStringValue())
+				}
+				switch {
+				case column.Column == "type" && filter.Params["types"] == "":
+					filter.Params["types"] = strings.Join(values, ",")
+					continue
+				case column.Column == "severity" && filter.Params["severity"] == "" && len(values) == 1:
+					filter.Params["severity"] = values[0]
+					continue
+				}
+			}
+			if term, ok := column.term(qual.Operator, qual.Value); ok {
+				terms = append(terms, term)
+			}
+		}
+	}
+	filter.Search = strings.Join(terms, " and ")
+
+	// the endpoint only returns the installable errata, unless it is asked to
+	// include the applicable ones too
+	if installable, ok := quals["installable"]; ok && installable != nil {
+		for _, qual := range installable.Quals {
+			if qual.Operator == "=" && !qual.Value.GetBoolValue() {
+				filter.Params["include_applicable"] = "true"
+			}
+		}
+	}
+
+	if cves, ok := quals["cve"]; ok && cves != nil {
+		for _, qual := range cves.Quals {
+			if qual.Operator != "=" {
+				continue
+			}
+			for _, value := range qualValues(qual.Value) {
+				filter.CVEs = append(filter.CVEs, value.GetStringValue())
+			}
+		}
+	}
+	return filter
+}
+
 //// LIST FUNCTIONS
 
 func listSatelliteHostErrata(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -186,143 +295,79 @@ func listSatelliteHostErrata(ctx context.Context, d *plugin.QueryData, h *plugin
 		return nil, err
 	}
 
-	// id := ""
-	// hostid := int(d.EqualsQuals["host_id"].GetInt64Value())
-	// hostname := d.EqualsQuals["host_name"].GetStringValue()
-	// if hostid == 0 {
-	// 	hostid, err = resolveHostID(ctx, d, hostname)
-	// 	if err != nil {
-	// 		plugin.Logger(ctx).Error("error resolving host by name", "name", hostname)
-	// 		return nil, err
-	// 	}
-	// }
-	// id = fmt.Sprintf("%d", hostid)
-	// if id == "" {
-	// 	plugin.Logger(ctx).Error("no valid host id or name provided")
-	// 	return nil, errors.New("no valid host id or name provided")
-	// }
-
-	hosts := []apiHost{}
+	filter := newErrataFilter(d.Quals)
+	plugin.Logger(ctx).Debug("pushing quals down into errata requests", "params", filter.Params, "search", filter.Search, "cves", filter.CVEs)
 
-	// first, check if running against a single host
-	single := apiHost{
-		ID:   int(d.EqualsQuals["host_id"].GetInt64Value()),
-		Name: d.EqualsQuals["host_name"].GetStringValue(),
-	}
-	if single.ID != 0 {
-		plugin.Logger(ctx).Debug("running query against single host", "host", utils.ToPrettyJSON(single))
-		hosts = append(hosts, single)
-	} else if single.Name != "" {
-		id, err := resolveHostID(ctx, d, single.Name)
-		if err != nil {
-			plugin.Logger(ctx).Error("error resolving host by name", "name", single.Name)
-			return nil, err
-		}
-		single.ID = id
-		hosts = append(hosts, single)
-	} else {
-		hosts, err = listSatelliteHostImpl(ctx, client, true)
-		if err != nil {
-			plugin.Logger(ctx).Error("error getting list of hosts", "error", err)
-			return nil, err
-		}
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostErrata) bool) error {
+		return listSatelliteHostErrataImpl(ctx, d, client, host, filter, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving errata", "error", err)
+		return nil, err
 	}
+	return nil, nil
+}
 
-	plugin.Logger(ctx).Debug("retrieving errata from hosts", "hosts", utils.ToPrettyJSON(hosts))
-
-outer:
-	for _, host := range hosts {
-		host := host
-
-		id := fmt.Sprintf("%d", host.ID)
+// listSatelliteHostErrataImpl streams the errata of the given host that match
+// the given filter, one CVE at a time if the filter has any.
+func listSatelliteHostErrataImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, host apiHost, filter errataFilter, stream func(*hostErrata) bool) error {
+	id := fmt.Sprintf("%d", host.ID)
 
-		plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
+	plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
 
-		page := 1
+	cves := filter.CVEs
+	if len(cves) == 0 {
+		cves = []string{""}
+	}
+	for _, cve := range cves {
+		terms := []string{}
+		if filter.Search != "" {
+			terms = append(terms, filter.Search)
+		}
+		if cve != "" {
+			terms = append(terms, fmt.Sprintf("cve = %s", quote(cve)))
+		}
+		search := strings.Join(terms, " and ")
 
-		for {
-			request := client.
-				R().
-				SetContext(ctx).
+		more := true
+		err := paginate(ctx, client, GetPerPage(d.Connection), "/api/hosts/{id}/errata", func(request *resty.Request) {
+			request.
 				SetPathParam("id", id).
-				SetQueryParam("page", fmt.Sprintf("%d", page))
-
-			request.SetHeaders(map[string]string{
-				"Accept-Encoding": "gzip",
-				"Accept":          "text/html",
-			})
-			result := &struct {
-				Total    int         `json:"total"`
-				Subtotal int         `json:"subtotal"`
-				Page     interface{} `json:"page"`
-				PerPage  int         `json:"per_page"`
-				Error    interface{} `json:"error"`
-				Search   interface{} `json:"search"`
-				Sort     struct {
-					By    string `json:"by"`
-					Order string `json:"order"`
-				} `json:"sort"`
-				Errata []apiErrata `json:"results"`
-			}{}
-			request.SetResult(result)
-			response, err := request.Get("/api/hosts/{id}/errata")
-			if err != nil || response.IsError() {
-				plugin.Logger(ctx).Error("error performing request", "url", response.Request.URL, "status", response, response.Status(), "error", err, "response", utils.ToPrettyJSON(response.Body()))
-				return nil, fmt.Errorf("request %q failed with status %d (%s): %w", response.Request.URL, response.StatusCode(), response.Status(), err)
-			}
-			plugin.Logger(ctx).Debug("request successful", "total", result.Total, "subtotal", result.Subtotal, "page", result.Page, "per page", result.PerPage, "response", utils.ToJSON(response.Body()))
-
-			for _, errata := range result.Errata {
-				if ctx.Err() != nil {
-					plugin.Logger(ctx).Debug("context done, exit")
-					break outer
-				}
-
-				errata := errata
-				//plugin.Logger(ctx).Debug("package", "contents", toJSON(pkg))
-				d.StreamListItem(ctx, &struct {
-					HostID   int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
-					HostName string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
-					apiErrata
-				}{
-					HostID:    host.ID,
-					HostName:  host.Name,
-					apiErrata: errata,
+				SetQueryParams(filter.Params).
+				SetHeaders(map[string]string{
+					"Accept-Encoding": "gzip",
+					"Accept":          "text/html",
 				})
+			if search != "" {
+				request.SetQueryParam("search", search)
 			}
-
-			// handle pagination. Note that the Satellite API returns results.Page as an
-			// integer if there is no page?{page} query  parameter, and as a string if you
-			// set one; thus we need to handle both cases
-			resultPage := 0
-			switch v := result.Page.(type) {
-			case int:
-				resultPage = v
-			case int32:
-				resultPage = int(v)
-			case int64:
-				resultPage = int(v)
-			case float32:
-				resultPage = int(v)
-			case float64:
-				resultPage = int(v)
-			case string:
-				resultPage, _ = strconv.Atoi(v)
-			default:
-				plugin.Logger(ctx).Debug("unsupported type in pagination", "type", fmt.Sprintf("%T", result.Page))
-				return nil, fmt.Errorf("unexpected type in pagination API result: %T", result.Page)
-			}
-			if result.PerPage*resultPage < result.Total {
-				page++
-				plugin.Logger(ctx).Debug("retrieving next page", "page", page)
-			} else {
-				plugin.Logger(ctx).Debug("all pages retrieved", "subtotal", result.Subtotal, "total", result.Total)
-				break
-			}
+		}, func(errata apiErrata) bool {
+			more = stream(&hostErrata{
+				HostID:           host.ID,
+				HostName:         host.Name,
+				OrganizationID:   host.OrganizationID,
+				OrganizationName: host.OrganizationName,
+				CVE:              cve,
+				apiErrata:        errata,
+			})
+			return more
+		})
+		if err != nil || !more {
+			return err
 		}
 	}
+	return nil
+}
 
-	return nil, nil
+// hostErrata is an erratum as streamed to the table, along with the host it
+// applies to.
+type hostErrata struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	CVE              string `json:"cve,omitempty" yaml:"cve,omitempty"`
+	apiErrata
 }
 
 type apiErrata struct {
@@ -330,8 +375,8 @@ type apiErrata struct {
 	PulpID          string `json:"pulp_id"`
 	Title           string `json:"title"`
 	ErrataID        string `json:"errata_id"`
-	Issued          string `json:"issued"`
-	Updated         string `json:"updated"`
+	Issued          *Time  `json:"issued"`
+	Updated         *Time  `json:"updated"`
 	Severity        string `json:"severity"`
 	Description     string `json:"description"`
 	Solution        string `json:"solution"`
@@ -341,7 +386,7 @@ type apiErrata struct {
 	Name            string `json:"name"`
 	Type            string `json:"type"`
 	CVEs            []struct {
-		ID   string `json:"bug_id"`
+		ID   string `json:"cve_id"`
 		Href string `json:"href"`
 	} `json:"cves"`
 	Bugs []struct {
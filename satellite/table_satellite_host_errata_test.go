@@ -0,0 +1,105 @@
+package satellite
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestNewErrataFilter(t *testing.T) {
+	issued := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		quals    plugin.KeyColumnQualMap
+		expected errataFilter
+	}{
+		{
+			quals:    qualMap(),
+			expected: errataFilter{Params: map[string]string{}},
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "type", Operator: "=", Value: stringQual("security")},
+				&quals.Qual{Column: "severity", Operator: "=", Value: stringQual("Critical")},
+			),
+			expected: errataFilter{Params: map[string]string{"types": "security", "severity": "Critical"}},
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "type", Operator: "=", Value: listQual("bugfix", "enhancement")},
+				&quals.Qual{Column: "severity", Operator: "=", Value: listQual("Critical", "Important")},
+			),
+			expected: errataFilter{
+				Params: map[string]string{"types": "bugfix,enhancement"},
+				Search: `severity ^ ("Critical", "Important")`,
+			},
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "type", Operator: "<>", Value: stringQual("enhancement")},
+				&quals.Qual{Column: "errata_id", Operator: "=", Value: stringQual("RHSA-2023:0001")},
+				&quals.Qual{Column: "issued_at", Operator: ">=", Value: &proto.QualValue{Value: &proto.QualValue_TimestampValue{TimestampValue: timestamppb.New(issued)}}},
+			),
+			expected: errataFilter{
+				Params: map[string]string{},
+				Search: `type != "enhancement" and errata_id = "RHSA-2023:0001" and issued >= "2023-05-01 00:00:00 UTC"`,
+			},
+		},
+		{
+			quals: qualMap(
+				&quals.Qual{Column: "installable", Operator: "=", Value: &proto.QualValue{Value: &proto.QualValue_BoolValue{BoolValue: false}}},
+				&quals.Qual{Column: "cve", Operator: "=", Value: listQual("CVE-2023-1234", "CVE-2023-5678")},
+			),
+			expected: errataFilter{
+				Params: map[string]string{"include_applicable": "true"},
+				CVEs:   []string{"CVE-2023-1234", "CVE-2023-5678"},
+			},
+		},
+	}
+	for _, test := range tests {
+		actual := newErrataFilter(test.quals)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Fatalf("error: expected %+v, got %+v", test.expected, actual)
+		}
+	}
+}
+
+func TestListSatelliteHostErrataByCVE(t *testing.T) {
+	searches := []string{}
+	client := newTestClient(t, map[string]string{
+		"/api/hosts/1/errata": `{"total":1,"subtotal":1,"page":1,"per_page":20,"results":[{"id":1,"errata_id":"RHSA-2023:0001"}]}`,
+	}, func(r *http.Request) bool {
+		if r.URL.Query().Get("types") != "security" {
+			return false
+		}
+		searches = append(searches, r.URL.Query().Get("search"))
+		return true
+	})
+
+	filter := errataFilter{
+		Params: map[string]string{"types": "security"},
+		Search: `severity = "Critical"`,
+		CVEs:   []string{"CVE-2023-1234", "CVE-2023-5678"},
+	}
+	cves := []string{}
+	err := listSatelliteHostErrataImpl(testContext(), &plugin.QueryData{}, client, apiHost{ID: 1}, filter, func(errata *hostErrata) bool {
+		cves = append(cves, errata.CVE)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cves, filter.CVEs) {
+		t.Fatalf("error: unexpected CVEs %v", cves)
+	}
+	expected := []string{`severity = "Critical" and cve = "CVE-2023-1234"`, `severity = "Critical" and cve = "CVE-2023-5678"`}
+	if !reflect.DeepEqual(searches, expected) {
+		t.Fatalf("error: unexpected searches %v", searches)
+	}
+}
@@ -0,0 +1,349 @@
+package satellite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteHostFact(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_host_fact",
+		Description: "Red Hat Satellite Host Facts",
+		Columns: []*plugin.Column{
+			{
+				Name:        "fact_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the fact; nested facts are flattened, with their path separated by '::', e.g. os::release::major.",
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "fact_short_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The last component of the name of the fact, e.g. major.",
+				Transform:   transform.FromField("ShortName"),
+			},
+			{
+				Name:        "value",
+				Type:        proto.ColumnType_STRING,
+				Description: "The value of the fact.",
+				Transform:   transform.FromField("Value"),
+			},
+			{
+				Name:        "origin",
+				Type:        proto.ColumnType_STRING,
+				Description: "The source the fact was reported by, e.g. Puppet, Ansible or RHSM; Satellite does not report it, so it is only set when the query filters on it (e.g. origin = 'Ansible'), at the cost of one listing per origin.",
+				Transform:   transform.FromField("Origin").Transform(transform.NullIfZeroValue),
+			},
+			// join columns
+			{
+				Name:        "host_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the host the fact was reported for.",
+				Hydrate:     getSatelliteFactHost,
+				Transform:   transform.FromField("ID"),
+			},
+			{
+				Name:        "host_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the host the fact was reported for.",
+				Transform:   transform.FromField("HostName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteHostFact,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(append(factSearchColumns.KeyColumns(),
+				&plugin.KeyColumn{
+					Name:    "host_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "origin",
+					Require: plugin.Optional,
+				},
+			), organizationKeyColumns()...),
+		},
+	}
+}
+
+// factSearchColumns lists the columns whose quals are pushed down into the
+// scoped search of /api/fact_values; host_id and origin are handled apart.
+var factSearchColumns = searchColumns{
+	{Column: "host_name", Field: "host", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "fact_name", Field: "fact", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "fact_short_name", Field: "fact_short_name", Kind: searchString, Operators: []string{"=", "<>"}},
+	{Column: "value", Field: "value", Kind: searchString, Operators: []string{"=", "<>"}},
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteHostFact(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite fact list", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	terms := []string{}
+	if search := factSearchColumns.Search(d.Quals); search != "" {
+		terms = append(terms, search)
+	}
+	if ids, ok := d.EqualsQuals["host_id"]; ok {
+		term, err := factHostTerm(ctx, d, client, qualValues(ids))
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+	search := strings.Join(terms, " and ")
+	plugin.Logger(ctx).Debug("pushing quals down into scoped search", "search", search)
+
+	err = listSatelliteHostFactImpl(ctx, d, client, search, factOriginsOf(d), func(fact *hostFact) bool {
+		d.StreamListItem(ctx, fact)
+		return d.RowsRemaining(ctx) != 0
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving facts", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// factHostTerm translates the host_id qual into a search term on the host
+// names, which is what /api/fact_values can search on.
+func factHostTerm(ctx context.Context, d *plugin.QueryData, client *resty.Client, ids []*proto.QualValue) (string, error) {
+	index, err := getHostIndex(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving host index", "error", err)
+		return "", err
+	}
+	names := []string{}
+	for _, id := range ids {
+		if host, ok := index.ByID[int(id.GetInt64Value())]; ok {
+			names = append(names, quote(host.Name))
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("unknown host ids: %w", ErrNotFound)
+	}
+	return fmt.Sprintf("host ^ (%s)", strings.Join(names, ", ")), nil
+}
+
+// factOriginsOf returns the origins in the origin qual, if any, for the facts
+// to be listed one origin at a time, since /api/fact_values does not report
+// the origin of the facts but can search on it. Without a qual, a nil slice
+// is returned: the facts are listed all at once and their origin is unknown,
+// since finding it out would take a listing per known origin.
+func factOriginsOf(d *plugin.QueryData) []string {
+	origins, ok := d.EqualsQuals["origin"]
+	if !ok {
+		return nil
+	}
+	result := []string{}
+	for _, origin := range qualValues(origins) {
+		result = append(result, origin.GetStringValue())
+	}
+	return result
+}
+
+// listSatelliteHostFactImpl streams the facts matching the given scoped
+// search, one organization and, if origins is not nil, one origin at a time.
+func listSatelliteHostFactImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, search string, origins []string, stream func(*hostFact) bool) error {
+	organizations, scoped, err := getOrganizations(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving organizations", "error", err)
+		return err
+	}
+	if !scoped {
+		organizations = []apiTaxonomy{{}}
+	}
+
+	searches := []string{search}
+	if origins != nil {
+		searches = []string{}
+		for _, origin := range origins {
+			term := fmt.Sprintf("origin = %s", quote(origin))
+			if search != "" {
+				term = search + " and " + term
+			}
+			searches = append(searches, term)
+		}
+	} else {
+		origins = []string{""}
+	}
+
+	for _, organization := range organizations {
+		for i, origin := range origins {
+			more := true
+			err := paginateResults(ctx, client, GetPerPage(d.Connection), "/api/fact_values", func(request *resty.Request) {
+				if organization.ID != 0 {
+					request.SetQueryParam(organizationTaxonomy.Param, strconv.Itoa(organization.ID))
+				}
+				if searches[i] != "" {
+					request.SetQueryParam("search", searches[i])
+				}
+			}, unfoldFacts, func(fact factValue) bool {
+				more = stream(&hostFact{
+					HostName:         fact.Host,
+					Name:             fact.Name,
+					ShortName:        shortFactName(fact.Name),
+					Value:            fact.Value,
+					Origin:           origin,
+					OrganizationID:   organization.ID,
+					OrganizationName: organization.Name,
+				})
+				return more
+			})
+			if err != nil {
+				return err
+			}
+			if !more || ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// factValue is a single fact of a host, as unfolded from the results of
+// /api/fact_values.
+type factValue struct {
+	Host  string
+	Name  string
+	Value string
+}
+
+// unfoldFacts turns the results of a page of /api/fact_values, which are not
+// a list but the facts of each host by host name, into the facts in the page,
+// sorted by host and fact name.
+func unfoldFacts(results map[string]facts) []factValue {
+	values := []factValue{}
+	for _, host := range sortedKeys(results) {
+		for _, name := range sortedKeys(results[host]) {
+			values = append(values, factValue{Host: host, Name: name, Value: results[host][name]})
+		}
+	}
+	return values
+}
+
+//// HYDRATE FUNCTIONS
+
+// getSatelliteFactHost hydrates the host a fact was reported for, which
+// /api/fact_values only reports by name.
+func getSatelliteFactHost(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	fact := h.Item.(*hostFact)
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	index, err := getHostIndex(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving host index", "error", err)
+		return nil, err
+	}
+	if host, ok := index.lookup(fact.HostName); ok {
+		return host, nil
+	}
+	return nil, nil
+}
+
+// FactSeparator separates the components of the names of nested facts, as
+// in Foreman.
+const FactSeparator = "::"
+
+// facts are the facts of a host by name; nested facts are flattened into
+// names made of their path, and values that are not strings are encoded as
+// JSON, so that the facts look the same whatever the source and the
+// Satellite version that reported them.
+type facts map[string]string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *facts) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	values := map[string]interface{}{}
+	if err := decoder.Decode(&values); err != nil {
+		return err
+	}
+	*f = facts{}
+	for name, value := range values {
+		f.flatten(name, value)
+	}
+	return nil
+}
+
+// flatten adds a fact, or the facts nested in it.
+func (f facts) flatten(name string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			f[name] = ""
+		}
+		for key, nested := range v {
+			f.flatten(name+FactSeparator+key, nested)
+		}
+	case string:
+		f[name] = v
+	case nil:
+		f[name] = ""
+	case json.Number:
+		f[name] = v.String()
+	case bool:
+		f[name] = strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		f[name] = string(encoded)
+	}
+}
+
+// shortFactName returns the last component of the name of a nested fact.
+func shortFactName(name string) string {
+	if i := strings.LastIndex(name, FactSeparator); i >= 0 {
+		return name[i+len(FactSeparator):]
+	}
+	return name
+}
+
+// hostFact is a fact as streamed to the table, along with the host it was
+// reported for.
+type hostFact struct {
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	Name             string `json:"name,omitempty" yaml:"name,omitempty"`
+	ShortName        string `json:"short_name,omitempty" yaml:"short_name,omitempty"`
+	Value            string `json:"value,omitempty" yaml:"value,omitempty"`
+	Origin           string `json:"origin,omitempty" yaml:"origin,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+}
@@ -0,0 +1,92 @@
+package satellite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestFactsFlattening(t *testing.T) {
+	actual := facts{}
+	err := json.Unmarshal([]byte(`{
+		"kernelrelease": "4.18.0-477.10.1.el8_8.x86_64",
+		"os::family": "RedHat",
+		"os": {"release": {"major": "8", "minor": 8}, "selinux": {}},
+		"is_virtual": true,
+		"processors::models": ["Xeon", "Xeon"],
+		"uptime_seconds": 12345678901,
+		"dmi::bios::vendor": null
+	}`), &actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := facts{
+		"kernelrelease":      "4.18.0-477.10.1.el8_8.x86_64",
+		"os::family":         "RedHat",
+		"os::release::major": "8",
+		"os::release::minor": "8",
+		"os::selinux":        "",
+		"is_virtual":         "true",
+		"processors::models": `["Xeon","Xeon"]`,
+		"uptime_seconds":     "12345678901",
+		"dmi::bios::vendor":  "",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("error: expected %v, got %v", expected, actual)
+	}
+
+	if shortFactName("os::release::major") != "major" || shortFactName("kernelrelease") != "kernelrelease" {
+		t.Fatal("error: unexpected short fact names")
+	}
+}
+
+func TestPaginateFacts(t *testing.T) {
+	searches := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/fact_values" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		searches = append(searches, r.URL.Query().Get("search"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `{"total":10,"subtotal":3,"page":1,"per_page":2,"results":{
+				"web01.example.com": {"kernelrelease": "4.18.0-477.el8.x86_64"},
+				"web02.example.com": {"kernelrelease": "5.14.0-284.el9.x86_64"}
+			}}`)
+		default:
+			fmt.Fprint(w, `{"total":10,"subtotal":3,"page":2,"per_page":2,"results":{
+				"web03.example.com": {"kernelrelease": "5.14.0-362.el9.x86_64"}
+			}}`)
+		}
+	}))
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	hosts := []string{}
+	err := paginateResults(testContext(), client, 2, "/api/fact_values", func(request *resty.Request) {
+		request.SetQueryParam("search", `fact = "kernelrelease"`)
+	}, unfoldFacts, func(fact factValue) bool {
+		if fact.Name != "kernelrelease" || fact.Value == "" {
+			t.Fatalf("error: unexpected fact %q = %q", fact.Name, fact.Value)
+		}
+		hosts = append(hosts, fact.Host)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hosts, []string{"web01.example.com", "web02.example.com", "web03.example.com"}) {
+		t.Fatalf("error: unexpected hosts %v", hosts)
+	}
+	if len(searches) != 2 || searches[0] != `fact = "kernelrelease"` {
+		t.Fatalf("error: unexpected searches %v", searches)
+	}
+}
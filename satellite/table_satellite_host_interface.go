@@ -0,0 +1,398 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteHostInterface(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_host_interface",
+		Description: "Red Hat Satellite Host Network Interfaces",
+		Columns: []*plugin.Column{
+			{
+				Name:        "id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the interface.",
+				Transform:   transform.FromField("ID"),
+			},
+			{
+				Name:        "identifier",
+				Type:        proto.ColumnType_STRING,
+				Description: "The device identifier of the interface, e.g. eth0.",
+				Transform:   transform.FromField("Identifier"),
+			},
+			{
+				Name:        "name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The DNS name of the interface.",
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "fqdn",
+				Type:        proto.ColumnType_STRING,
+				Description: "The fully qualified domain name of the interface.",
+				Transform:   transform.FromField("FQDN"),
+			},
+			{
+				Name:        "type",
+				Type:        proto.ColumnType_STRING,
+				Description: "The type of the interface, e.g. interface, bond or bridge.",
+				Transform:   transform.FromField("Type"),
+			},
+			{
+				Name:        "mac_address",
+				Type:        proto.ColumnType_STRING,
+				Description: "The MAC address of the interface.",
+				Transform:   transform.FromField("MAC"),
+			},
+			{
+				Name:        "ipv4_address",
+				Type:        proto.ColumnType_INET,
+				Description: "The IPv4 address of the interface.",
+				Transform:   transform.FromField("IPv4"),
+			},
+			{
+				Name:        "ipv6_address",
+				Type:        proto.ColumnType_INET,
+				Description: "The IPv6 address of the interface.",
+				Transform:   transform.FromField("IPv6"),
+			},
+			{
+				Name:        "mtu",
+				Type:        proto.ColumnType_INT,
+				Description: "The MTU of the interface.",
+				Transform:   transform.FromField("MTU"),
+			},
+			{
+				Name:        "primary",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the interface is the primary one, used for the host name.",
+				Transform:   transform.FromField("Primary"),
+			},
+			{
+				Name:        "provision",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the interface is used for provisioning.",
+				Transform:   transform.FromField("Provision"),
+			},
+			{
+				Name:        "managed",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether DNS and DHCP are managed by Satellite for the interface.",
+				Transform:   transform.FromField("Managed"),
+			},
+			{
+				Name:        "virtual",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the interface is a virtual one, e.g. an alias or a VLAN.",
+				Transform:   transform.FromField("Virtual"),
+			},
+			{
+				Name:        "execution",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the interface is used for remote execution.",
+				Transform:   transform.FromField("Execution"),
+			},
+			{
+				Name:        "tag",
+				Type:        proto.ColumnType_STRING,
+				Description: "The VLAN tag of the interface.",
+				Transform:   transform.FromField("Tag"),
+			},
+			{
+				Name:        "attached_to",
+				Type:        proto.ColumnType_STRING,
+				Description: "The identifier of the interface a virtual interface is attached to.",
+				Transform:   transform.FromField("AttachedTo"),
+			},
+			{
+				Name:        "attached_devices",
+				Type:        proto.ColumnType_STRING,
+				Description: "The identifiers of the interfaces a bond or a bridge is made of.",
+				Transform:   transform.FromField("AttachedDevices"),
+			},
+			{
+				Name:        "mode",
+				Type:        proto.ColumnType_STRING,
+				Description: "The mode of a bond interface.",
+				Transform:   transform.FromField("Mode"),
+			},
+			{
+				Name:        "bond_options",
+				Type:        proto.ColumnType_STRING,
+				Description: "The options of a bond interface.",
+				Transform:   transform.FromField("BondOptions"),
+			},
+			{
+				Name:        "domain_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the domain of the interface.",
+				Transform:   transform.FromField("DomainID"),
+			},
+			{
+				Name:        "domain_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the domain of the interface.",
+				Transform:   transform.FromField("DomainName"),
+			},
+			{
+				Name:        "subnet_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the IPv4 subnet of the interface.",
+				Transform:   transform.FromField("SubnetID"),
+			},
+			{
+				Name:        "subnet_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the IPv4 subnet of the interface.",
+				Transform:   transform.FromField("SubnetName"),
+			},
+			{
+				Name:        "subnet_cidr",
+				Type:        proto.ColumnType_CIDR,
+				Description: "The network address of the IPv4 subnet of the interface.",
+				Hydrate:     getSatelliteInterfaceSubnets,
+				Transform:   transform.FromField("Subnet"),
+			},
+			{
+				Name:        "subnet6_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the IPv6 subnet of the interface.",
+				Transform:   transform.FromField("Subnet6ID"),
+			},
+			{
+				Name:        "subnet6_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the IPv6 subnet of the interface.",
+				Transform:   transform.FromField("Subnet6Name"),
+			},
+			{
+				Name:        "subnet6_cidr",
+				Type:        proto.ColumnType_CIDR,
+				Description: "The network address of the IPv6 subnet of the interface.",
+				Hydrate:     getSatelliteInterfaceSubnets,
+				Transform:   transform.FromField("Subnet6"),
+			},
+			{
+				Name:        "created_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The interface's creation time.",
+				Transform:   transform.FromField("CreatedAt").Transform(ToTimestamp),
+			},
+			{
+				Name:        "updated_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The interface's update time.",
+				Transform:   transform.FromField("UpdatedAt").Transform(ToTimestamp),
+			},
+			// join columns
+			{
+				Name:        "host_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the host having the interface.",
+				Transform:   transform.FromField("HostID"),
+			},
+			{
+				Name:        "host_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the host having the interface. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
+				Transform:   transform.FromField("HostName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteHostInterface,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(plugin.KeyColumnSlice{
+				&plugin.KeyColumn{
+					Name:    "host_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "host_name",
+					Require: plugin.Optional,
+				},
+			}, organizationKeyColumns()...),
+		},
+	}
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteHostInterface(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite interface list", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostInterface) bool) error {
+		return listSatelliteHostInterfaceImpl(ctx, d, client, host, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving interfaces", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// listSatelliteHostInterfaceImpl streams the network interfaces of the given
+// host.
+func listSatelliteHostInterfaceImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, host apiHost, stream func(*hostInterface) bool) error {
+	id := fmt.Sprintf("%d", host.ID)
+
+	plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
+
+	return paginate(ctx, client, GetPerPage(d.Connection), "/api/hosts/{id}/interfaces", func(request *resty.Request) {
+		request.SetPathParam("id", id)
+	}, func(nic apiInterface) bool {
+		return stream(&hostInterface{
+			HostID:           host.ID,
+			HostName:         host.Name,
+			OrganizationID:   host.OrganizationID,
+			OrganizationName: host.OrganizationName,
+			apiInterface:     nic,
+		})
+	})
+}
+
+//// HYDRATE FUNCTIONS
+
+// getSatelliteInterfaceSubnets hydrates the network addresses of the subnets
+// of an interface, which the interfaces endpoint does not report.
+func getSatelliteInterfaceSubnets(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	nic := h.Item.(*hostInterface)
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	subnets, err := getSubnets(ctx, d, client)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving subnets", "error", err)
+		return nil, err
+	}
+	return &interfaceSubnets{
+		Subnet:  subnets[nic.SubnetID].CIDR(),
+		Subnet6: subnets[nic.Subnet6ID].CIDR(),
+	}, nil
+}
+
+const SatelliteSubnetsKey = "satellite_subnets"
+
+// getSubnets returns the subnets by ID, retrieving them the first time and
+// then caching them in the connection cache.
+func getSubnets(ctx context.Context, d *plugin.QueryData, client *resty.Client) (map[int]apiSubnet, error) {
+	if cachedData, ok := d.ConnectionManager.Cache.Get(SatelliteSubnetsKey); ok {
+		plugin.Logger(ctx).Debug("returning subnets from cache")
+		return cachedData.(map[int]apiSubnet), nil
+	}
+
+	subnets := map[int]apiSubnet{}
+	err := paginate(ctx, client, GetPerPage(d.Connection), "/api/subnets", nil, func(subnet apiSubnet) bool {
+		subnets[subnet.ID] = subnet
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plugin.Logger(ctx).Debug("saving subnets to cache", "subnets", len(subnets))
+	d.ConnectionManager.Cache.Set(SatelliteSubnetsKey, subnets)
+	return subnets, nil
+}
+
+// hostInterface is a network interface as streamed to the table, along with
+// the host it belongs to.
+type hostInterface struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	apiInterface
+}
+
+// interfaceSubnets are the network addresses of the subnets of an interface.
+type interfaceSubnets struct {
+	Subnet  string
+	Subnet6 string
+}
+
+type apiInterface struct {
+	SubnetID        int    `json:"subnet_id,omitempty" yaml:"subnet_id,omitempty"`
+	SubnetName      string `json:"subnet_name,omitempty" yaml:"subnet_name,omitempty"`
+	Subnet6ID       int    `json:"subnet6_id,omitempty" yaml:"subnet6_id,omitempty"`
+	Subnet6Name     string `json:"subnet6_name,omitempty" yaml:"subnet6_name,omitempty"`
+	DomainID        int    `json:"domain_id,omitempty" yaml:"domain_id,omitempty"`
+	DomainName      string `json:"domain_name,omitempty" yaml:"domain_name,omitempty"`
+	CreatedAt       *Time  `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	UpdatedAt       *Time  `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+	Managed         bool   `json:"managed,omitempty" yaml:"managed,omitempty"`
+	Identifier      string `json:"identifier,omitempty" yaml:"identifier,omitempty"`
+	ID              int    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name            string `json:"name,omitempty" yaml:"name,omitempty"`
+	IPv4            string `json:"ip,omitempty" yaml:"ip,omitempty"`
+	IPv6            string `json:"ip6,omitempty" yaml:"ip6,omitempty"`
+	MAC             string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	MTU             int    `json:"mtu,omitempty" yaml:"mtu,omitempty"`
+	FQDN            string `json:"fqdn,omitempty" yaml:"fqdn,omitempty"`
+	Primary         bool   `json:"primary,omitempty" yaml:"primary,omitempty"`
+	Provision       bool   `json:"provision,omitempty" yaml:"provision,omitempty"`
+	Type            string `json:"type,omitempty" yaml:"type,omitempty"`
+	Execution       bool   `json:"execution,omitempty" yaml:"execution,omitempty"`
+	Mode            string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	AttachedDevices string `json:"attached_devices,omitempty" yaml:"attached_devices,omitempty"`
+	BondOptions     string `json:"bond_options,omitempty" yaml:"bond_options,omitempty"`
+	Virtual         bool   `json:"virtual,omitempty" yaml:"virtual,omitempty"`
+	Tag             string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	AttachedTo      string `json:"attached_to,omitempty" yaml:"attached_to,omitempty"`
+}
+
+type apiSubnet struct {
+	ID             int    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name           string `json:"name,omitempty" yaml:"name,omitempty"`
+	NetworkType    string `json:"network_type,omitempty" yaml:"network_type,omitempty"`
+	Network        string `json:"network,omitempty" yaml:"network,omitempty"`
+	Mask           string `json:"mask,omitempty" yaml:"mask,omitempty"`
+	Prefix         int    `json:"cidr,omitempty" yaml:"cidr,omitempty"`
+	NetworkAddress string `json:"network_address,omitempty" yaml:"network_address,omitempty"`
+}
+
+// CIDR returns the network address of the subnet in CIDR notation, or an
+// empty string if it is not known.
+func (s apiSubnet) CIDR() string {
+	if s.NetworkAddress != "" {
+		return s.NetworkAddress
+	}
+	if s.Network != "" && s.Prefix > 0 {
+		return fmt.Sprintf("%s/%d", s.Network, s.Prefix)
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package satellite
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestListSatelliteHostInterface(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		"/api/hosts/42/interfaces": `{"total":2,"subtotal":2,"page":"1","per_page":100,"results":[
+			{"id":1,"identifier":"eth0","ip":"192.0.2.10","mac":"52:54:00:12:34:56","primary":true,"subnet_id":3,"subnet_name":"prod"},
+			{"id":2,"identifier":"eth0.100","type":"interface","virtual":true,"tag":"100","attached_to":"eth0"}
+		]}`,
+	}, nil)
+
+	rows := []*hostInterface{}
+	err := listSatelliteHostInterfaceImpl(testContext(), &plugin.QueryData{}, client, apiHost{ID: 42, Name: "web01.example.com", OrganizationID: 1}, func(row *hostInterface) bool {
+		rows = append(rows, row)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("error: expected 2 interfaces, got %d", len(rows))
+	}
+	if rows[0].HostName != "web01.example.com" || rows[0].OrganizationID != 1 || rows[0].IPv4 != "192.0.2.10" || !rows[0].Primary || rows[0].SubnetID != 3 {
+		t.Fatalf("error: unexpected interface %+v", rows[0])
+	}
+	if rows[1].Tag != "100" || rows[1].AttachedTo != "eth0" || !rows[1].Virtual {
+		t.Fatalf("error: unexpected interface %+v", rows[1])
+	}
+}
+
+func TestSubnetCIDR(t *testing.T) {
+	tests := []struct {
+		subnet   apiSubnet
+		expected string
+	}{
+		{subnet: apiSubnet{NetworkAddress: "192.0.2.0/24", Network: "192.0.2.0", Prefix: 24}, expected: "192.0.2.0/24"},
+		{subnet: apiSubnet{Network: "2001:db8::", Prefix: 64}, expected: "2001:db8::/64"},
+		{subnet: apiSubnet{}, expected: ""},
+	}
+	for _, test := range tests {
+		if actual := test.subnet.CIDR(); actual != test.expected {
+			t.Fatalf("error: expected %q, got %q", test.expected, actual)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
 	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -34,33 +35,78 @@ func tableSatelliteHostPackage(_ context.Context) *plugin.Table {
 				Name:        "version",
 				Type:        proto.ColumnType_STRING,
 				Description: "The version of the package.",
-				Transform: transform.FromField("NVRA").Transform(func(ctx context.Context, d *transform.TransformData) (interface{}, error) {
-					_, ver, _, _, err := ParseNVRA(d.Value.(string))
-					return ver, err
-				}),
+				Transform:   transform.FromField("Parsed.Package.Version"),
 			},
 			{
 				Name:        "release",
 				Type:        proto.ColumnType_STRING,
 				Description: "The release of the package.",
-				Transform: transform.FromField("NVRA").Transform(func(ctx context.Context, d *transform.TransformData) (interface{}, error) {
-					_, _, rel, _, err := ParseNVRA(d.Value.(string))
-					return rel, err
-				}),
+				Transform:   transform.FromField("Parsed.Package.Release"),
 			},
 			{
 				Name:        "architecture",
 				Type:        proto.ColumnType_STRING,
 				Description: "The architecture of the package.",
-				Transform: transform.FromField("NVRA").Transform(func(ctx context.Context, d *transform.TransformData) (interface{}, error) {
-					_, _, _, arch, err := ParseNVRA(d.Value.(string))
-					return arch, err
-				}),
+				Transform:   transform.FromField("Parsed.Package.Arch"),
+			},
+			{
+				Name:        "epoch",
+				Type:        proto.ColumnType_INT,
+				Description: "The epoch of the package.",
+				Transform:   transform.FromField("Parsed.Epoch"),
+			},
+			{
+				Name:        "evr",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Epoch, Version and Release (EVR) of the package, as [epoch:]version-release.",
+				Transform:   transform.FromField("Parsed.EVR"),
+			},
+			{
+				Name:        "evr_sort_key",
+				Type:        proto.ColumnType_STRING,
+				Description: "A key that sorts as rpm sorts the EVRs of the package, to be used in ORDER BY.",
+				Transform:   transform.FromField("Parsed.EVRSortKey"),
+			},
+			{
+				Name:        "dist_tag",
+				Type:        proto.ColumnType_STRING,
+				Description: "The distribution tag in the release of the package, e.g. el8_4, el8ost or module+el8.4.0+14872+9efa52a3.",
+				Transform:   transform.FromField("Parsed.Release.DistTag").Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "dist_major",
+				Type:        proto.ColumnType_INT,
+				Description: "The major release of the distribution the package was built for, e.g. 8.",
+				Transform:   transform.FromField("Parsed.Release.DistMajor"),
+			},
+			{
+				Name:        "dist_minor",
+				Type:        proto.ColumnType_INT,
+				Description: "The minor release of the distribution the package was built for, if it was built for a specific one, e.g. 4 in el8_4.",
+				Transform:   transform.FromField("Parsed.Release.DistMinor"),
+			},
+			{
+				Name:        "is_zstream",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the package was built for the z-stream of a minor release, e.g. el8_4.",
+				Transform:   transform.FromField("Parsed.Release.ZStream"),
+			},
+			{
+				Name:        "module_build",
+				Type:        proto.ColumnType_INT,
+				Description: "The ID of the module build that produced the package, e.g. 14872 in module+el8.4.0+14872+9efa52a3.",
+				Transform:   transform.FromField("Parsed.Release.ModuleBuild"),
+			},
+			{
+				Name:        "product_suffix",
+				Type:        proto.ColumnType_STRING,
+				Description: "The suffix of the layered product the package was built for, e.g. ost, ae or fdp.",
+				Transform:   transform.FromField("Parsed.Release.Product").Transform(transform.NullIfZeroValue),
 			},
 			{
 				Name:        "nvrea",
 				Type:        proto.ColumnType_STRING,
-				Description: "The Name, Version, Release, Environment and Architecture (NVREA) of the package.",
+				Description: "The Name, Epoch, Version, Release and Architecture (NVREA) of the package.",
 				Transform:   transform.FromField("NVREA"),
 			},
 			{
@@ -78,13 +124,28 @@ func tableSatelliteHostPackage(_ context.Context) *plugin.Table {
 			{
 				Name:        "host_name",
 				Type:        proto.ColumnType_STRING,
-				Description: "The name of the host having the package.",
+				Description: "The name of the host having the package. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
 				Transform:   transform.FromField("HostName"),
 			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
 		},
 		List: &plugin.ListConfig{
 			Hydrate: listSatelliteHostPackage,
-			KeyColumns: plugin.KeyColumnSlice{
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(plugin.KeyColumnSlice{
 				&plugin.KeyColumn{
 					Name:    "host_id",
 					Require: plugin.Optional,
@@ -93,7 +154,7 @@ func tableSatelliteHostPackage(_ context.Context) *plugin.Table {
 					Name:    "host_name",
 					Require: plugin.Optional,
 				},
-			},
+			}, organizationKeyColumns()...),
 		},
 	}
 }
@@ -110,127 +171,110 @@ func listSatelliteHostPackage(ctx context.Context, d *plugin.QueryData, h *plugi
 		return nil, err
 	}
 
-	hosts := []apiHost{}
-
-	// first, check if running against a single host
-	single := apiHost{
-		ID:   int(d.EqualsQuals["host_id"].GetInt64Value()),
-		Name: d.EqualsQuals["host_name"].GetStringValue(),
-	}
-	if single.ID != 0 {
-		plugin.Logger(ctx).Debug("running query against single host", "host", utils.ToPrettyJSON(single))
-		hosts = append(hosts, single)
-	} else if single.Name != "" {
-		id, err := resolveHostID(ctx, d, single.Name)
-		if err != nil {
-			plugin.Logger(ctx).Error("error resolving host by name", "name", single.Name)
-			return nil, err
-		}
-		single.ID = id
-		hosts = append(hosts, single)
-	} else {
-		hosts, err = listSatelliteHostImpl(ctx, client, true)
-		if err != nil {
-			plugin.Logger(ctx).Error("error getting list of hosts", "error", err)
-			return nil, err
-		}
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostPackage) bool) error {
+		return listSatelliteHostPackageImpl(ctx, d, client, host, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving packages", "error", err)
+		return nil, err
 	}
+	return nil, nil
+}
 
-	plugin.Logger(ctx).Debug("retrieving packages from hosts", "hosts", utils.ToPrettyJSON(hosts))
-
-outer:
-	for _, host := range hosts {
-		host := host
-
-		id := fmt.Sprintf("%d", host.ID)
+// listSatelliteHostPackageImpl streams the packages of the given host.
+func listSatelliteHostPackageImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, host apiHost, stream func(*hostPackage) bool) error {
+	id := fmt.Sprintf("%d", host.ID)
 
-		plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
+	plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
 
-		page := 1
-		for {
-			request := client.
-				R().
-				SetContext(ctx).
-				SetPathParam("id", id).
-				SetQueryParam("page", fmt.Sprintf("%d", page))
-			request.SetHeaders(map[string]string{
+	return paginate(ctx, client, GetPerPage(d.Connection), "/api/hosts/{id}/packages", func(request *resty.Request) {
+		request.
+			SetPathParam("id", id).
+			SetHeaders(map[string]string{
 				"Accept-Encoding": "gzip",
 				"Accept":          "text/html",
 			})
-			result := &struct {
-				Total    int         `json:"total"`
-				Subtotal int         `json:"subtotal"`
-				Page     interface{} `json:"page"`
-				PerPage  int         `json:"per_page"`
-				Error    interface{} `json:"error"`
-				Search   interface{} `json:"search"`
-				Sort     struct {
-					By    string `json:"by"`
-					Order string `json:"order"`
-				} `json:"sort"`
-				Packages []apiHostPackage `json:"results"`
-			}{}
-			request.SetResult(result)
-			plugin.Logger(ctx).Debug("running request", "id", id)
-			// response, err := request.Get(fmt.Sprintf("/hosts/{id}/packages?page=%d", page))
-			response, err := request.Get("/api/hosts/{id}/packages")
-
-			if err != nil || response.IsError() {
-				plugin.Logger(ctx).Error("error performing request", "status", response.Status(), "error", err, "response", utils.ToPrettyJSON(response.Body()))
-				return nil, fmt.Errorf("request %q error %d (%s): %w", request.URL, response.StatusCode(), response.Status(), err)
-			}
-			plugin.Logger(ctx).Debug("request successful", "status", response.Status(), "total", result.Total, "subtotal", result.Subtotal, "page", result.Page, "per page", result.PerPage, "response", utils.ToJSON(response.Body()))
-
-			for _, pkg := range result.Packages {
-				if ctx.Err() != nil {
-					plugin.Logger(ctx).Debug("context done, exit")
-					break outer
-				}
-				pkg := pkg
-				plugin.Logger(ctx).Debug("package", "contents", utils.ToPrettyJSON(pkg))
-				d.StreamListItem(ctx, &struct {
-					HostID   int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
-					HostName string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
-					apiHostPackage
-				}{
-					HostID:         host.ID,
-					HostName:       host.Name,
-					apiHostPackage: pkg,
-				})
-			}
-
-			// handle pagination; note that the Satellite API returns results.Page as an
-			// integer if there is no page?{page} query  parameter, and as a string if you
-			// set one; thus we need to handle both cases
-			resultPage := 0
-			switch v := result.Page.(type) {
-			case int:
-				resultPage = v
-			case int32:
-				resultPage = int(v)
-			case int64:
-				resultPage = int(v)
-			case float32:
-				resultPage = int(v)
-			case float64:
-				resultPage = int(v)
-			case string:
-				resultPage, _ = strconv.Atoi(v)
-			default:
-				plugin.Logger(ctx).Debug("unsupported type in pagination", "type", fmt.Sprintf("%T", result.Page))
-				return nil, fmt.Errorf("unexpected type in pagination API result: %T", result.Page)
-			}
-			if result.PerPage*resultPage < result.Total {
-				page++
-				plugin.Logger(ctx).Debug("retrieving next page", "page", page)
-			} else {
-				plugin.Logger(ctx).Debug("all pages retrieved", "subtotal", result.Subtotal, "total", result.Total)
-				break
-			}
+	}, func(pkg apiHostPackage) bool {
+		row := &hostPackage{
+			HostID:           host.ID,
+			HostName:         host.Name,
+			OrganizationID:   host.OrganizationID,
+			OrganizationName: host.OrganizationName,
+			apiHostPackage:   pkg,
+		}
+		// packages whose identity cannot be parsed, such as gpg-pubkey
+		// entries, are logged and have the derived columns set to null, so
+		// that a single one does not fail the whole query
+		parsed, err := parseHostPackage(row)
+		if err != nil {
+			plugin.Logger(ctx).Warn("error parsing package, returning null", "nvra", pkg.NVRA, "nvrea", pkg.NVREA, "error", err)
+		} else {
+			row.Parsed = parsed
 		}
+		return stream(row)
+	})
+}
+
+//// TRANSFORM FUNCTIONS
+
+// packageIdentity parses the identity of a package from its NVREA, or from
+// its NVRA if the API does not report the former.
+func packageIdentity(pkg *hostPackage) (Package, error) {
+	nvrea := pkg.NVREA
+	if nvrea == "" {
+		nvrea = pkg.NVRA
+	}
+	identity, err := ParsePackage(nvrea)
+	if err != nil {
+		return Package{}, err
 	}
+	if identity.Epoch == "" {
+		identity.Epoch = pkg.Epoch
+	}
+	return identity, nil
+}
 
-	return nil, nil
+// parseHostPackage parses the identity of a package once, when it is listed,
+// and computes the columns derived from it.
+func parseHostPackage(pkg *hostPackage) (*parsedPackage, error) {
+	identity, err := packageIdentity(pkg)
+	if err != nil {
+		return nil, err
+	}
+	evr := identity.EVR()
+	epoch, err := strconv.Atoi(evr.epoch())
+	if err != nil {
+		return nil, fmt.Errorf("invalid epoch %q: %w", evr.epoch(), err)
+	}
+	return &parsedPackage{
+		Package:    identity,
+		Epoch:      epoch,
+		EVR:        evr.String(),
+		EVRSortKey: evr.SortKey(),
+		Release:    ParseRelease(identity.Release),
+	}, nil
+}
+
+// hostPackage is a package as streamed to the table, along with the host it
+// is installed on.
+type hostPackage struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	// Parsed is nil if the identity of the package cannot be parsed.
+	Parsed *parsedPackage `json:"-" yaml:"-"`
+	apiHostPackage
+}
+
+// parsedPackage is the identity of a package, along with the columns derived
+// from it.
+type parsedPackage struct {
+	Package    Package
+	Epoch      int
+	EVR        string
+	EVRSortKey string
+	Release    Release
 }
 
 type apiHostPackage struct {
@@ -238,4 +282,9 @@ type apiHostPackage struct {
 	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
 	NVREA string `json:"nvrea,omitempty" yaml:"nvrea,omitempty"`
 	NVRA  string `json:"nvra,omitempty" yaml:"nvra,omitempty"`
+	// these are only reported by recent versions of Katello
+	Epoch   string `json:"epoch,omitempty" yaml:"epoch,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Release string `json:"release,omitempty" yaml:"release,omitempty"`
+	Arch    string `json:"arch,omitempty" yaml:"arch,omitempty"`
 }
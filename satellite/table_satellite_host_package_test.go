@@ -0,0 +1,72 @@
+package satellite
+
+import (
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestPackageIdentity(t *testing.T) {
+	tests := []struct {
+		pkg      apiHostPackage
+		expected Package
+	}{
+		{
+			pkg:      apiHostPackage{NVRA: "bash-4.4.20-1.el8_4.x86_64", NVREA: "bash-4.4.20-1.el8_4.x86_64"},
+			expected: Package{Name: "bash", Version: "4.4.20", Release: "1.el8_4", Arch: "x86_64"},
+		},
+		{
+			pkg:      apiHostPackage{NVRA: "dbus-1.12.8-12.el8_4.2.x86_64", NVREA: "dbus-1:1.12.8-12.el8_4.2.x86_64"},
+			expected: Package{Name: "dbus", Epoch: "1", Version: "1.12.8", Release: "12.el8_4.2", Arch: "x86_64"},
+		},
+		{
+			pkg:      apiHostPackage{NVRA: "dbus-1.12.8-12.el8_4.2.x86_64", Epoch: "1"},
+			expected: Package{Name: "dbus", Epoch: "1", Version: "1.12.8", Release: "12.el8_4.2", Arch: "x86_64"},
+		},
+	}
+	for _, test := range tests {
+		actual, err := packageIdentity(&hostPackage{apiHostPackage: test.pkg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Fatalf("error: %q: expected %+v, got %+v", test.pkg.NVREA, test.expected, actual)
+		}
+	}
+
+	if _, err := packageIdentity(&hostPackage{apiHostPackage: apiHostPackage{NVRA: "gpg-pubkey"}}); err == nil {
+		t.Fatal("error: expected invalid package")
+	}
+}
+
+func TestParseHostPackage(t *testing.T) {
+	parsed, err := parseHostPackage(&hostPackage{apiHostPackage: apiHostPackage{NVRA: "dbus-1.12.8-12.el8_4.2.x86_64", NVREA: "dbus-1:1.12.8-12.el8_4.2.x86_64"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Epoch != 1 || parsed.EVR != "1:1.12.8-12.el8_4.2" || parsed.EVRSortKey != parsed.Package.EVR().SortKey() {
+		t.Fatalf("error: unexpected parsed package %+v", parsed)
+	}
+	if parsed.Release.DistTag != "el8_4" || *parsed.Release.DistMajor != 8 || *parsed.Release.DistMinor != 4 || !parsed.Release.ZStream {
+		t.Fatalf("error: unexpected parsed release %+v", parsed.Release)
+	}
+
+	parsed, err = parseHostPackage(&hostPackage{apiHostPackage: apiHostPackage{NVRA: "bash-4.4.20-1.el8_4.x86_64"}})
+	if err != nil || parsed.Epoch != 0 {
+		t.Fatalf("error: expected epoch 0, got %+v (%v)", parsed, err)
+	}
+}
+
+func TestInvalidPackageColumns(t *testing.T) {
+	// packages whose identity cannot be parsed are streamed without it
+	pkg := &hostPackage{apiHostPackage: apiHostPackage{Name: "gpg-pubkey", NVRA: "gpg-pubkey"}}
+	for _, column := range tableSatelliteHostPackage(testContext()).Columns {
+		value, err := column.Transform.Execute(testContext(), &transform.TransformData{HydrateItem: pkg, ColumnName: column.Name})
+		if err != nil {
+			t.Fatalf("error: column %q: unexpected error %v", column.Name, err)
+		}
+		if column.Name == "version" && value != nil {
+			t.Fatalf("error: expected null version, got %v", value)
+		}
+	}
+}
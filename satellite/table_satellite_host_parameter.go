@@ -0,0 +1,451 @@
+package satellite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"gopkg.in/yaml.v3"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteHostParameter(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_host_parameter",
+		Description: "Red Hat Satellite Host Parameters, including inherited ones",
+		Columns: []*plugin.Column{
+			{
+				Name:        "id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the parameter.",
+				Transform:   transform.FromField("ID"),
+			},
+			{
+				Name:        "name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the parameter.",
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "value",
+				Type:        proto.ColumnType_JSON,
+				Description: "The value of the parameter, decoded according to its type; hidden values are masked.",
+				Transform:   transform.From(decodeParameterValue),
+			},
+			{
+				Name:        "parameter_type",
+				Type:        proto.ColumnType_STRING,
+				Description: "The type of the parameter, e.g. string, boolean, integer, real, array, hash, yaml or json.",
+				Transform:   transform.FromField("ParameterType"),
+			},
+			{
+				Name:        "hidden",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether the value of the parameter is hidden.",
+				Transform:   transform.FromField("HiddenValue"),
+			},
+			{
+				Name:        "level",
+				Type:        proto.ColumnType_STRING,
+				Description: "The level the parameter is defined at: global, organization, location, domain, subnet, os, hostgroup or host.",
+				Transform:   transform.FromField("Level"),
+			},
+			{
+				Name:        "priority",
+				Type:        proto.ColumnType_INT,
+				Description: "The priority of the level the parameter is defined at; higher levels override lower ones.",
+				Transform:   transform.FromField("Priority"),
+			},
+			{
+				Name:        "overridden",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether a parameter with the same name at a higher level overrides this one.",
+				Transform:   transform.FromField("Overridden"),
+			},
+			{
+				Name:        "created_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The parameter's creation time.",
+				Transform:   transform.FromField("CreatedAt").Transform(ToTimestamp),
+			},
+			{
+				Name:        "updated_at",
+				Type:        proto.ColumnType_TIMESTAMP,
+				Description: "The parameter's update time.",
+				Transform:   transform.FromField("UpdatedAt").Transform(ToTimestamp),
+			},
+			// join columns
+			{
+				Name:        "host_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the host the parameter applies to.",
+				Transform:   transform.FromField("HostID"),
+			},
+			{
+				Name:        "host_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the host the parameter applies to. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
+				Transform:   transform.FromField("HostName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteHostParameter,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(plugin.KeyColumnSlice{
+				&plugin.KeyColumn{
+					Name:    "host_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "host_name",
+					Require: plugin.Optional,
+				},
+			}, organizationKeyColumns()...),
+		},
+	}
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteHostParameter(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite parameter list", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	// the parameters of the levels are shared by many hosts
+	cache := &parameterCache{}
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostParameter) bool) error {
+		return listSatelliteHostParameterImpl(ctx, d, client, cache, host, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving parameters", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// listSatelliteHostParameterImpl streams the parameters applying to the given
+// host, at all levels. The host only reports the parameters that win, so
+// those of the other levels are listed one level at a time.
+func listSatelliteHostParameterImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, cache *parameterCache, host apiHost, stream func(*hostParameter) bool) error {
+	plugin.Logger(ctx).Debug("running query against host", "id", host.ID, "name", host.Name)
+
+	detail, err := getSatelliteHostImpl(ctx, client, fmt.Sprintf("%d", host.ID))
+	if err != nil {
+		return err
+	}
+	sources, err := parameterSources(ctx, client, cache, detail.apiHost)
+	if err != nil {
+		return err
+	}
+
+	parameters := []*hostParameter{}
+	for _, source := range sources {
+		values, err := cache.get(source.URL, func() (interface{}, error) {
+			values := []apiParameter{}
+			err := paginate(ctx, client, GetPerPage(d.Connection), source.URL, nil, func(parameter apiParameter) bool {
+				values = append(values, parameter)
+				return true
+			})
+			return values, err
+		})
+		if err != nil {
+			return err
+		}
+		for _, parameter := range values.([]apiParameter) {
+			parameters = append(parameters, newHostParameter(source, parameter))
+		}
+	}
+	for _, parameter := range detail.Parameters {
+		parameters = append(parameters, newHostParameter(parameterSource{Level: "host"}, parameter))
+	}
+
+	for _, parameter := range resolveParameters(parameters) {
+		parameter.HostID = host.ID
+		parameter.HostName = host.Name
+		parameter.OrganizationID = host.OrganizationID
+		parameter.OrganizationName = host.OrganizationName
+		if !stream(parameter) {
+			break
+		}
+	}
+	return nil
+}
+
+// parameterPriorities maps the levels parameters can be defined at onto the
+// priorities Foreman assigns to them; higher levels override lower ones.
+var parameterPriorities = map[string]int{
+	"global":       0,
+	"organization": 10,
+	"location":     20,
+	"domain":       30,
+	"subnet":       40,
+	"os":           50,
+	"hostgroup":    60,
+	"host":         70,
+}
+
+// parameterSource is a level whose parameters apply to a host, along with
+// the URL they are listed at.
+type parameterSource struct {
+	Level string
+	URL   string
+}
+
+// parameterSources returns the levels whose parameters apply to the given
+// host, except the host itself, from the lowest to the highest priority; the
+// host groups go from the root to the host's own, since nested host groups
+// override their ancestors.
+func parameterSources(ctx context.Context, client *resty.Client, cache *parameterCache, host apiHost) ([]parameterSource, error) {
+	sources := []parameterSource{{Level: "global", URL: "/api/common_parameters"}}
+	add := func(level string, collection string, id int) {
+		if id != 0 {
+			sources = append(sources, parameterSource{Level: level, URL: fmt.Sprintf("/api/%s/%d/parameters", collection, id)})
+		}
+	}
+	add("organization", "organizations", host.OrganizationID)
+	add("location", "locations", host.LocationID)
+	add("domain", "domains", host.DomainID)
+	add("subnet", "subnets", intValue(host.SubnetID))
+	add("subnet", "subnets", intValue(host.Subnet6ID))
+	add("os", "operatingsystems", host.OperatingSystemID)
+	if host.HostGroupID != 0 {
+		ancestors, err := cache.get(fmt.Sprintf("/api/hostgroups/%d", host.HostGroupID), func() (interface{}, error) {
+			return getHostGroupAncestors(ctx, client, host.HostGroupID)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ancestors.([]int) {
+			add("hostgroup", "hostgroups", id)
+		}
+		add("hostgroup", "hostgroups", host.HostGroupID)
+	}
+	return sources, nil
+}
+
+// getHostGroupAncestors returns the IDs of the ancestors of a host group,
+// from the root down.
+func getHostGroupAncestors(ctx context.Context, client *resty.Client, id int) ([]int, error) {
+	group := &apiHostGroup{}
+	response, err := client.
+		R().
+		SetContext(ctx).
+		SetPathParam("id", fmt.Sprintf("%d", id)).
+		SetResult(group).
+		Get("/api/hostgroups/{id}")
+	if err != nil || response.IsError() {
+		plugin.Logger(ctx).Error("error performing request", "url", "/api/hostgroups/{id}", "id", id, "status", response.Status(), "error", err)
+		return nil, requestError(response, err)
+	}
+
+	ancestors := []int{}
+	if group.Ancestry != nil {
+		for _, ancestor := range strings.Split(*group.Ancestry, "/") {
+			if id, err := strconv.Atoi(ancestor); err == nil {
+				ancestors = append(ancestors, id)
+			}
+		}
+	}
+	return ancestors, nil
+}
+
+// newHostParameter returns a parameter defined at the level of the given
+// source.
+func newHostParameter(source parameterSource, parameter apiParameter) *hostParameter {
+	parameter.Priority = parameterPriorities[source.Level]
+	return &hostParameter{apiParameter: parameter, Level: source.Level}
+}
+
+// resolveParameters marks the parameters that are overridden by a parameter
+// with the same name at a higher level or, at the same level, by one that
+// comes later; the parameters must be sorted by increasing priority.
+func resolveParameters(parameters []*hostParameter) []*hostParameter {
+	effective := map[string]*hostParameter{}
+	for _, parameter := range parameters {
+		if current, ok := effective[parameter.Name]; !ok || parameter.Priority >= current.Priority {
+			effective[parameter.Name] = parameter
+		}
+	}
+	for _, parameter := range parameters {
+		parameter.Overridden = effective[parameter.Name] != parameter
+	}
+	return parameters
+}
+
+// parameterCache holds what is shared by the hosts of a query, by URL; it is
+// safe for concurrent use, although the same URL may be retrieved more than
+// once by hosts queried at the same time.
+type parameterCache struct {
+	lock   sync.Mutex
+	values map[string]interface{}
+}
+
+// get returns the value cached for the given URL, retrieving it with fetch if
+// it is not cached yet.
+func (c *parameterCache) get(url string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.lock.Lock()
+	value, ok := c.values[url]
+	c.lock.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.values == nil {
+		c.values = map[string]interface{}{}
+	}
+	c.values[url] = value
+	return value, nil
+}
+
+// intValue returns the integer in an optional numeric field, or 0.
+func intValue(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+//// TRANSFORM FUNCTIONS
+
+// hiddenParameterValue replaces the values of hidden parameters, as Foreman
+// does in its UI.
+const hiddenParameterValue = "*****"
+
+// decodeParameterValue decodes the value of a parameter according to its
+// type; values that cannot be decoded are returned as they are. The SDK
+// passes strings into JSON columns as raw JSON, so string values are returned
+// already encoded.
+func decodeParameterValue(ctx context.Context, d *transform.TransformData) (interface{}, error) {
+	parameter := d.HydrateItem.(*hostParameter)
+	if parameter.HiddenValue {
+		return jsonValue(hiddenParameterValue)
+	}
+	value, err := decodeParameter(parameter.ParameterType, parameter.Value)
+	if err != nil {
+		plugin.Logger(ctx).Warn("error decoding parameter value, returning it as is", "name", parameter.Name, "type", parameter.ParameterType, "error", err)
+		return jsonValue(parameter.Value)
+	}
+	return jsonValue(value)
+}
+
+// jsonValue encodes string values as JSON strings, leaving all other values
+// to the SDK.
+func jsonValue(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+// decodeParameter decodes a parameter value according to its type; the API
+// reports most values already decoded, but some Foreman versions report them
+// as they were entered, that is as strings.
+func decodeParameter(parameterType string, value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	parameterType = strings.ToLower(parameterType)
+	switch parameterType {
+	case "boolean":
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean value %q", s)
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "real":
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case "array", "hash", "json":
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			// Foreman also accepts arrays and hashes in YAML
+			if parameterType == "json" || yaml.Unmarshal([]byte(s), &decoded) != nil {
+				return nil, err
+			}
+		}
+		return decoded, nil
+	case "yaml":
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(s), &decoded); err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+	return s, nil
+}
+
+// hostParameter is a parameter as streamed to the table, along with the host
+// it applies to.
+type hostParameter struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	Level            string `json:"level,omitempty" yaml:"level,omitempty"`
+	Overridden       bool   `json:"overridden,omitempty" yaml:"overridden,omitempty"`
+	apiParameter
+}
+
+type apiHostGroup struct {
+	ID       int     `json:"id,omitempty" yaml:"id,omitempty"`
+	Ancestry *string `json:"ancestry,omitempty" yaml:"ancestry,omitempty"`
+}
+
+type apiParameter struct {
+	Priority       int         `json:"priority,omitempty" yaml:"priority,omitempty"`
+	CreatedAt      *Time       `json:"created_at,omitempty" yaml:"created_at,omitempty"`
+	UpdatedAt      *Time       `json:"updated_at,omitempty" yaml:"updated_at,omitempty"`
+	ID             int         `json:"id,omitempty" yaml:"id,omitempty"`
+	Name           string      `json:"name,omitempty" yaml:"name,omitempty"`
+	ParameterType  string      `json:"parameter_type" yaml:"parameter_type"`
+	Value          interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	HiddenValue    bool        `json:"hidden_value?,omitempty" yaml:"hidden_value?,omitempty"`
+	AssociatedType string      `json:"associated_type,omitempty" yaml:"associated_type,omitempty"`
+}
@@ -0,0 +1,129 @@
+package satellite
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestListSatelliteHostParameter(t *testing.T) {
+	responses := map[string]string{
+		"/api/hosts/1":                    `{"id": 1, "name": "web01.example.com", "organization_id": 1, "domain_id": 3, "subnet_id": 4, "hostgroup_id": 6, "parameters": [{"id": 7, "name": "ntp_server", "value": "ntp.dmz.example.com"}]}`,
+		"/api/common_parameters":          `{"total": 2, "subtotal": 2, "page": 1, "per_page": 20, "results": [{"id": 1, "name": "ntp_server", "value": "pool.ntp.org"}, {"id": 2, "name": "timezone", "value": "UTC"}]}`,
+		"/api/organizations/1/parameters": `{"total": 1, "subtotal": 1, "page": 1, "per_page": 20, "results": [{"id": 3, "name": "kt_activation_keys", "value": "ak-prod"}]}`,
+		"/api/domains/3/parameters":       `{"total": 1, "subtotal": 1, "page": 1, "per_page": 20, "results": [{"id": 4, "name": "ntp_server", "value": "ntp.example.com"}]}`,
+		"/api/subnets/4/parameters":       `{"total": 0, "subtotal": 0, "page": 1, "per_page": 20, "results": []}`,
+		"/api/hostgroups/6":               `{"id": 6, "ancestry": "5"}`,
+		"/api/hostgroups/5/parameters":    `{"total": 1, "subtotal": 1, "page": 1, "per_page": 20, "results": [{"id": 5, "name": "role", "value": "base"}]}`,
+		"/api/hostgroups/6/parameters":    `{"total": 1, "subtotal": 1, "page": 1, "per_page": 20, "results": [{"id": 6, "name": "role", "value": "web"}]}`,
+	}
+	client := newTestClient(t, responses, nil)
+
+	parameters := map[int]*hostParameter{}
+	err := listSatelliteHostParameterImpl(testContext(), &plugin.QueryData{}, client, &parameterCache{}, apiHost{ID: 1, Name: "web01"}, func(parameter *hostParameter) bool {
+		parameters[parameter.ID] = parameter
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int]struct {
+		level      string
+		overridden bool
+	}{
+		1: {level: "global", overridden: true},
+		2: {level: "global"},
+		3: {level: "organization"},
+		4: {level: "domain", overridden: true},
+		5: {level: "hostgroup", overridden: true},
+		6: {level: "hostgroup"},
+		7: {level: "host"},
+	}
+	if len(parameters) != len(expected) {
+		t.Fatalf("error: expected %d parameters, got %d", len(expected), len(parameters))
+	}
+	for id, e := range expected {
+		parameter := parameters[id]
+		if parameter == nil || parameter.Level != e.level || parameter.Overridden != e.overridden || parameter.HostName != "web01" {
+			t.Fatalf("error: parameter %d: expected level %q (overridden %t), got %+v", id, e.level, e.overridden, parameter)
+		}
+	}
+	if parameters[7].Priority != 70 || parameters[4].Priority != 30 {
+		t.Fatalf("error: unexpected priorities %d and %d", parameters[7].Priority, parameters[4].Priority)
+	}
+}
+
+func TestDecodeParameter(t *testing.T) {
+	tests := []struct {
+		parameterType string
+		value         interface{}
+		expected      interface{}
+		err           bool
+	}{
+		{parameterType: "string", value: "web", expected: "web"},
+		{parameterType: "boolean", value: "yes", expected: true},
+		{parameterType: "boolean", value: false, expected: false},
+		{parameterType: "boolean", value: "maybe", err: true},
+		{parameterType: "integer", value: "42", expected: int64(42)},
+		{parameterType: "real", value: "0.5", expected: 0.5},
+		{parameterType: "array", value: `["a", "b"]`, expected: []interface{}{"a", "b"}},
+		{parameterType: "array", value: "- a\n- b\n", expected: []interface{}{"a", "b"}},
+		{parameterType: "hash", value: `{"a": 1}`, expected: map[string]interface{}{"a": float64(1)}},
+		{parameterType: "json", value: "a: 1", err: true},
+		{parameterType: "yaml", value: "a: b", expected: map[string]interface{}{"a": "b"}},
+	}
+	for _, test := range tests {
+		actual, err := decodeParameter(test.parameterType, test.value)
+		if (err != nil) != test.err {
+			t.Fatalf("error: %s %v: unexpected error %v", test.parameterType, test.value, err)
+		}
+		if err == nil && !reflect.DeepEqual(actual, test.expected) {
+			t.Fatalf("error: %s %v: expected %#v, got %#v", test.parameterType, test.value, test.expected, actual)
+		}
+	}
+}
+
+// jsonColumnValue mirrors how the SDK converts a value for a JSON column:
+// strings are taken as raw JSON, anything else is marshalled.
+func jsonColumnValue(value interface{}) ([]byte, error) {
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
+
+func TestDecodeParameterValue(t *testing.T) {
+	column := tableSatelliteHostParameter(testContext()).Columns[2]
+	if column.Name != "value" {
+		t.Fatalf("error: unexpected column %q", column.Name)
+	}
+
+	tests := []struct {
+		parameter apiParameter
+		expected  string
+	}{
+		{parameter: apiParameter{Name: "role", ParameterType: "string", Value: "web"}, expected: `"web"`},
+		{parameter: apiParameter{Name: "root_pass", ParameterType: "string", Value: "secret", HiddenValue: true}, expected: `"*****"`},
+		{parameter: apiParameter{Name: "port", ParameterType: "integer", Value: "not a number"}, expected: `"not a number"`},
+		{parameter: apiParameter{Name: "port", ParameterType: "integer", Value: "8080"}, expected: `8080`},
+		{parameter: apiParameter{Name: "enabled", ParameterType: "boolean", Value: true}, expected: `true`},
+		{parameter: apiParameter{Name: "servers", ParameterType: "array", Value: `["a", "b"]`}, expected: `["a","b"]`},
+	}
+	for _, test := range tests {
+		value, err := column.Transform.Execute(testContext(), &transform.TransformData{HydrateItem: &hostParameter{apiParameter: test.parameter}, ColumnName: column.Name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := jsonColumnValue(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !json.Valid(actual) || string(actual) != test.expected {
+			t.Fatalf("error: %s: expected %s, got %s", test.parameter.Name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package satellite
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func TestGetSatelliteHostDetails(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, map[string]string{
+		"/api/hosts/42": `{
+			"id": 42,
+			"name": "web01.example.com",
+			"organization_name": "ACME",
+			"facts": {"os::family": "RedHat"},
+			"parameters": [{"name": "role", "value": "web", "parameter_type": "string"}],
+			"interfaces": [{"identifier": "eth0", "ip": "192.0.2.10", "primary": true}]
+		}`,
+	}, func(r *http.Request) bool {
+		requests++
+		return true
+	})
+
+	host, err := getSatelliteHostImpl(testContext(), client, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host.ID != 42 || host.OrganizationName != "ACME" {
+		t.Fatalf("error: unexpected list-level fields %+v", host.apiHost)
+	}
+	if host.Facts["os::family"] != "RedHat" || len(host.Parameters) != 1 || len(host.Interfaces) != 1 || host.Interfaces[0].IPv4 != "192.0.2.10" {
+		t.Fatalf("error: unexpected detail-level fields %+v", host.apiHostDetails)
+	}
+
+	// rows retrieved via Get already have their details
+	detail, err := getSatelliteHostDetails(testContext(), nil, &plugin.HydrateData{Item: host})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail != host || requests != 1 {
+		t.Fatalf("error: expected host details to be reused, got %d requests", requests)
+	}
+
+	if _, err := getSatelliteHostImpl(testContext(), client, "43"); !isNotFoundError(testContext(), nil, nil, err) {
+		t.Fatalf("error: expected not found error, got %v", err)
+	}
+}
+
+func TestNullWithoutContentFacet(t *testing.T) {
+	registered := &apiHost{ID: 1}
+	registered.ContentFacetAttributes.ID = 7
+	unregistered := &apiHost{ID: 2}
+
+	tests := []struct {
+		item     interface{}
+		value    interface{}
+		expected interface{}
+	}{
+		{item: registered, value: 0, expected: 0},
+		{item: &hostDetail{apiHost: *registered}, value: 3, expected: 3},
+		{item: unregistered, value: 0, expected: nil},
+		{item: unregistered, value: false, expected: nil},
+	}
+	for _, test := range tests {
+		actual, err := nullWithoutContentFacet(testContext(), &transform.TransformData{HydrateItem: test.item, Value: test.value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Fatalf("error: expected %v, got %v", test.expected, actual)
+		}
+	}
+}
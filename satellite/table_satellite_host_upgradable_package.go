@@ -0,0 +1,365 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+//// TABLE DEFINITION
+
+func tableSatelliteHostUpgradablePackage(_ context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "satellite_host_upgradable_package",
+		Description: "Red Hat Satellite Host Upgradable Packages",
+		Columns: []*plugin.Column{
+			{
+				Name:        "package_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the package the installed one can be upgraded to.",
+				Transform:   transform.FromField("PackageID"),
+			},
+			{
+				Name:        "name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the package.",
+				Transform:   transform.FromField("Name"),
+			},
+			{
+				Name:        "architecture",
+				Type:        proto.ColumnType_STRING,
+				Description: "The architecture of the package.",
+				Transform:   transform.FromField("Arch"),
+			},
+			{
+				Name:        "installed_nvra",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Name, Version, Release and Architecture (NVRA) of the installed package.",
+				Transform:   transform.FromField("InstalledNVRA").Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "installed_evr",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Epoch, Version and Release (EVR) of the installed package.",
+				Transform:   transform.FromField("InstalledEVR").Transform(transform.NullIfZeroValue),
+			},
+			{
+				Name:        "upgradable_nvra",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Name, Version, Release and Architecture (NVRA) of the package the installed one can be upgraded to.",
+				Transform:   transform.FromField("UpgradableNVRA"),
+			},
+			{
+				Name:        "upgradable_evr",
+				Type:        proto.ColumnType_STRING,
+				Description: "The Epoch, Version and Release (EVR) of the package the installed one can be upgraded to.",
+				Transform:   transform.FromField("UpgradableEVR"),
+			},
+			{
+				Name:        "latest",
+				Type:        proto.ColumnType_BOOL,
+				Description: "Whether this is the latest version the installed package can be upgraded to.",
+				Transform:   transform.FromField("Latest"),
+			},
+			{
+				Name:        "errata_id",
+				Type:        proto.ColumnType_STRING,
+				Description: "The ID of the errata applicable to the host that delivers the upgrade, if any.",
+				Transform:   transform.FromField("ErrataID"),
+			},
+			{
+				Name:        "repository_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the repository the upgrade comes from.",
+				Hydrate:     getSatelliteUpgradablePackage,
+				Transform:   transform.FromField("Repositories").Transform(firstRepositoryName),
+			},
+			{
+				Name:        "repositories",
+				Type:        proto.ColumnType_JSON,
+				Description: "The repositories the upgrade is available in.",
+				Hydrate:     getSatelliteUpgradablePackage,
+				Transform:   transform.FromField("Repositories"),
+			},
+			// join columns
+			{
+				Name:        "host_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the host having the package.",
+				Transform:   transform.FromField("HostID"),
+			},
+			{
+				Name:        "host_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the host having the package. When filtering on host_name, this echoes the name in the query, which may be a short name, a certname or differ in case from the host's own name.",
+				Transform:   transform.FromField("HostName"),
+			},
+			{
+				Name:        "organization_id",
+				Type:        proto.ColumnType_INT,
+				Description: "The id of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationID"),
+			},
+			{
+				Name:        "organization_name",
+				Type:        proto.ColumnType_STRING,
+				Description: "The name of the organization the host belongs to.",
+				Transform:   transform.FromField("OrganizationName"),
+			},
+		},
+		List: &plugin.ListConfig{
+			Hydrate: listSatelliteHostUpgradablePackage,
+			IgnoreConfig: &plugin.IgnoreConfig{
+				ShouldIgnoreErrorFunc: isNotFoundError,
+			},
+			KeyColumns: append(plugin.KeyColumnSlice{
+				&plugin.KeyColumn{
+					Name:    "host_id",
+					Require: plugin.Optional,
+				},
+				&plugin.KeyColumn{
+					Name:    "host_name",
+					Require: plugin.Optional,
+				},
+			}, organizationKeyColumns()...),
+		},
+	}
+}
+
+//// LIST FUNCTIONS
+
+func listSatelliteHostUpgradablePackage(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	setLogLevel(ctx, d)
+	plugin.Logger(ctx).Debug("retrieving satellite upgradable package list for host", "query data", utils.ToJSON(d))
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	// errata are only retrieved if they are needed
+	withErrata := isRequested(d, "errata_id")
+
+	err = fanOutHosts(ctx, d, client, func(ctx context.Context, host apiHost, stream func(*hostUpgradablePackage) bool) error {
+		return listSatelliteHostUpgradablePackageImpl(ctx, d, client, host, withErrata, stream)
+	})
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving upgradable packages", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// listSatelliteHostUpgradablePackageImpl streams the packages that the given
+// host can be upgraded to, along with the installed packages they upgrade
+// and, if withErrata is set, the errata that deliver them.
+func listSatelliteHostUpgradablePackageImpl(ctx context.Context, d *plugin.QueryData, client *resty.Client, host apiHost, withErrata bool, stream func(*hostUpgradablePackage) bool) error {
+	id := fmt.Sprintf("%d", host.ID)
+
+	plugin.Logger(ctx).Debug("running query against host", "id", id, "name", host.Name)
+
+	candidates := []apiPackage{}
+	err := paginate(ctx, client, GetPerPage(d.Connection), "/katello/api/packages", func(request *resty.Request) {
+		request.
+			SetQueryParam("host_id", id).
+			SetQueryParam("packages_restrict_upgradable", "true")
+	}, func(pkg apiPackage) bool {
+		candidates = append(candidates, pkg)
+		return true
+	})
+	if err != nil || len(candidates) == 0 {
+		return err
+	}
+
+	installed := map[string][]Package{}
+	err = listSatelliteHostPackageImpl(ctx, d, client, host, func(pkg *hostPackage) bool {
+		// packages whose identity cannot be parsed have already been logged
+		if pkg.Parsed != nil {
+			identity := pkg.Parsed.Package
+			installed[identity.Name] = append(installed[identity.Name], identity)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	errata := map[string]string{}
+	if withErrata {
+		err = listSatelliteHostErrataImpl(ctx, d, client, host, errataFilter{}, func(e *hostErrata) bool {
+			for _, nvrea := range e.Packages {
+				if identity, err := ParsePackage(nvrea); err == nil {
+					errata[identity.NVRA()] = e.ErrataID
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range matchUpgrades(ctx, candidates, installed, errata) {
+		row.HostID = host.ID
+		row.HostName = host.Name
+		row.OrganizationID = host.OrganizationID
+		row.OrganizationName = host.OrganizationName
+		if !stream(row) {
+			break
+		}
+	}
+	return nil
+}
+
+// matchUpgrades pairs each candidate package with the installed package it
+// upgrades, that is the newest one with the same name and architecture that
+// is older than the candidate, and with the erratum that delivers it, if any;
+// errata are indexed by the NVRA of their packages, which has no epoch.
+// Candidates whose identity cannot be parsed are skipped.
+func matchUpgrades(ctx context.Context, candidates []apiPackage, installed map[string][]Package, errata map[string]string) []*hostUpgradablePackage {
+	rows := []*hostUpgradablePackage{}
+	latest := map[string]*hostUpgradablePackage{}
+	for _, candidate := range candidates {
+		upgrade, err := candidate.identity()
+		if err != nil {
+			plugin.Logger(ctx).Warn("error parsing upgradable package, skipping", "id", candidate.ID, "nvra", candidate.NVRA, "nvrea", candidate.NVREA, "error", err)
+			continue
+		}
+		row := &hostUpgradablePackage{
+			PackageID:      candidate.ID,
+			Name:           upgrade.Name,
+			Arch:           upgrade.Arch,
+			UpgradableNVRA: candidate.NVRA,
+			UpgradableEVR:  upgrade.EVR().String(),
+			ErrataID:       errata[upgrade.NVRA()],
+		}
+		if row.UpgradableNVRA == "" {
+			row.UpgradableNVRA = upgrade.NVRA()
+		}
+
+		var current *Package
+		for i, pkg := range installed[upgrade.Name] {
+			if pkg.Arch != upgrade.Arch && pkg.Arch != "noarch" && upgrade.Arch != "noarch" {
+				continue
+			}
+			if pkg.EVR().Compare(upgrade.EVR()) >= 0 {
+				continue
+			}
+			if current == nil || pkg.EVR().Compare(current.EVR()) > 0 {
+				current = &installed[upgrade.Name][i]
+			}
+		}
+		if current != nil {
+			row.InstalledNVRA = current.NVRA()
+			row.InstalledEVR = current.EVR().String()
+		}
+
+		key := upgrade.Name + "." + upgrade.Arch
+		if previous, ok := latest[key]; !ok || ParseEVR(previous.UpgradableEVR).Compare(upgrade.EVR()) < 0 {
+			latest[key] = row
+		}
+		rows = append(rows, row)
+	}
+	for _, row := range latest {
+		row.Latest = true
+	}
+	return rows
+}
+
+//// HYDRATE FUNCTIONS
+
+// getSatelliteUpgradablePackage hydrates the details of the package the
+// installed one can be upgraded to, which the listing does not report.
+func getSatelliteUpgradablePackage(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	row := h.Item.(*hostUpgradablePackage)
+
+	client, err := getClient(ctx, d)
+	if err != nil {
+		plugin.Logger(ctx).Error("error retrieving satellite client", "error", err)
+		return nil, err
+	}
+
+	pkg := &apiPackage{}
+	response, err := client.
+		R().
+		SetContext(ctx).
+		SetPathParam("id", fmt.Sprintf("%d", row.PackageID)).
+		SetResult(pkg).
+		Get("/katello/api/packages/{id}")
+	if err != nil || response.IsError() {
+		plugin.Logger(ctx).Error("error performing request", "url", "/katello/api/packages/{id}", "id", row.PackageID, "status", response.Status(), "error", err)
+		return nil, requestError(response, err)
+	}
+	return pkg, nil
+}
+
+//// TRANSFORM FUNCTIONS
+
+// firstRepositoryName returns the name of the first repository in the list.
+func firstRepositoryName(ctx context.Context, d *transform.TransformData) (interface{}, error) {
+	repositories, ok := d.Value.([]apiRepositoryRef)
+	if !ok || len(repositories) == 0 {
+		return nil, nil
+	}
+	return repositories[0].Name, nil
+}
+
+// hostUpgradablePackage is a package upgrade as streamed to the table, along
+// with the host it applies to.
+type hostUpgradablePackage struct {
+	HostID           int    `json:"host_id,omitempty" yaml:"host_id,omitempty"`
+	HostName         string `json:"host_name,omitempty" yaml:"host_name,omitempty"`
+	OrganizationID   int    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	OrganizationName string `json:"organization_name,omitempty" yaml:"organization_name,omitempty"`
+	PackageID        int    `json:"package_id,omitempty" yaml:"package_id,omitempty"`
+	Name             string `json:"name,omitempty" yaml:"name,omitempty"`
+	Arch             string `json:"arch,omitempty" yaml:"arch,omitempty"`
+	InstalledNVRA    string `json:"installed_nvra,omitempty" yaml:"installed_nvra,omitempty"`
+	InstalledEVR     string `json:"installed_evr,omitempty" yaml:"installed_evr,omitempty"`
+	UpgradableNVRA   string `json:"upgradable_nvra,omitempty" yaml:"upgradable_nvra,omitempty"`
+	UpgradableEVR    string `json:"upgradable_evr,omitempty" yaml:"upgradable_evr,omitempty"`
+	Latest           bool   `json:"latest,omitempty" yaml:"latest,omitempty"`
+	ErrataID         string `json:"errata_id,omitempty" yaml:"errata_id,omitempty"`
+}
+
+// apiPackage is a package in Katello's content.
+type apiPackage struct {
+	ID           int                `json:"id,omitempty" yaml:"id,omitempty"`
+	PulpID       string             `json:"pulp_id,omitempty" yaml:"pulp_id,omitempty"`
+	Name         string             `json:"name,omitempty" yaml:"name,omitempty"`
+	Epoch        string             `json:"epoch,omitempty" yaml:"epoch,omitempty"`
+	Version      string             `json:"version,omitempty" yaml:"version,omitempty"`
+	Release      string             `json:"release,omitempty" yaml:"release,omitempty"`
+	Arch         string             `json:"arch,omitempty" yaml:"arch,omitempty"`
+	NVRA         string             `json:"nvra,omitempty" yaml:"nvra,omitempty"`
+	NVREA        string             `json:"nvrea,omitempty" yaml:"nvrea,omitempty"`
+	Filename     string             `json:"filename,omitempty" yaml:"filename,omitempty"`
+	Summary      string             `json:"summary,omitempty" yaml:"summary,omitempty"`
+	SourceRPM    string             `json:"sourcerpm,omitempty" yaml:"sourcerpm,omitempty"`
+	Repositories []apiRepositoryRef `json:"repositories,omitempty" yaml:"repositories,omitempty"`
+}
+
+// identity returns the identity of the package, from its fields or else from
+// its NVREA or NVRA; an error is returned if neither can be parsed.
+func (p apiPackage) identity() (Package, error) {
+	if p.Name != "" && p.Version != "" && p.Release != "" && p.Arch != "" {
+		return Package{Name: p.Name, Epoch: p.Epoch, Version: p.Version, Release: p.Release, Arch: p.Arch, Source: p.Arch == "src" || p.Arch == "nosrc"}, nil
+	}
+	nvrea := p.NVREA
+	if nvrea == "" {
+		nvrea = p.NVRA
+	}
+	return ParsePackage(nvrea)
+}
+
+type apiRepositoryRef struct {
+	ID    int    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+}
@@ -0,0 +1,54 @@
+package satellite
+
+import (
+	"testing"
+)
+
+func TestMatchUpgrades(t *testing.T) {
+	candidates := []apiPackage{
+		{ID: 1, Name: "bash", Version: "4.4.20", Release: "4.el8_6", Arch: "x86_64", NVRA: "bash-4.4.20-4.el8_6.x86_64"},
+		{ID: 2, Name: "bash", Version: "4.4.20", Release: "5.el8", Arch: "x86_64", NVRA: "bash-4.4.20-5.el8.x86_64"},
+		{ID: 3, Name: "glibc", Version: "2.28", Release: "225.el8", Arch: "i686"},
+		{ID: 4, Name: "glibc", Version: "2.28", Release: "225.el8", Arch: "x86_64"},
+		{ID: 5, NVREA: "perl-IO-1:1.38-422.el8.x86_64"},
+		{ID: 6, Name: "tzdata", Epoch: "2", Version: "2023c", Release: "1.el8", Arch: "noarch"},
+		{ID: 7, Name: "broken"},
+	}
+	installed := map[string][]Package{
+		"bash":    {{Name: "bash", Version: "4.4.20", Release: "3.el8", Arch: "x86_64"}},
+		"glibc":   {{Name: "glibc", Version: "2.28", Release: "211.el8", Arch: "x86_64"}, {Name: "glibc", Version: "2.28", Release: "189.el8", Arch: "i686"}},
+		"perl-IO": {{Name: "perl-IO", Epoch: "1", Version: "1.38", Release: "420.el8", Arch: "x86_64"}},
+	}
+	errata := map[string]string{
+		"bash-4.4.20-5.el8.x86_64":  "RHBA-2023:1234",
+		"tzdata-2023c-1.el8.noarch": "RHBA-2023:5678",
+	}
+
+	expected := map[int]struct {
+		installed  string
+		upgradable string
+		latest     bool
+		errata     string
+	}{
+		1: {installed: "bash-4.4.20-3.el8.x86_64", upgradable: "bash-4.4.20-4.el8_6.x86_64"},
+		2: {installed: "bash-4.4.20-3.el8.x86_64", upgradable: "bash-4.4.20-5.el8.x86_64", latest: true, errata: "RHBA-2023:1234"},
+		3: {installed: "glibc-2.28-189.el8.i686", upgradable: "glibc-2.28-225.el8.i686", latest: true},
+		4: {installed: "glibc-2.28-211.el8.x86_64", upgradable: "glibc-2.28-225.el8.x86_64", latest: true},
+		5: {installed: "perl-IO-1.38-420.el8.x86_64", upgradable: "perl-IO-1.38-422.el8.x86_64", latest: true},
+		6: {upgradable: "tzdata-2023c-1.el8.noarch", latest: true, errata: "RHBA-2023:5678"},
+	}
+
+	rows := matchUpgrades(testContext(), candidates, installed, errata)
+	if len(rows) != len(expected) {
+		t.Fatalf("error: expected %d rows, got %d", len(expected), len(rows))
+	}
+	for _, row := range rows {
+		e := expected[row.PackageID]
+		if row.InstalledNVRA != e.installed || row.UpgradableNVRA != e.upgradable || row.Latest != e.latest || row.ErrataID != e.errata {
+			t.Fatalf("error: package %d: expected %+v, got %+v", row.PackageID, e, row)
+		}
+	}
+	if rows[4].InstalledEVR != "1:1.38-420.el8" || rows[4].UpgradableEVR != "1:1.38-422.el8" {
+		t.Fatalf("error: unexpected EVRs %q and %q", rows[4].InstalledEVR, rows[4].UpgradableEVR)
+	}
+}
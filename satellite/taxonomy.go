@@ -0,0 +1,132 @@
+package satellite
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// taxonomy describes one of the Satellite entities that hosts and content
+// can be scoped to, i.e. organizations and locations.
+type taxonomy struct {
+	// Attribute is the name of the connection attribute.
+	Attribute string
+	// Entity is the name of the entity, as used by hammer.
+	Entity string
+	// URL is the collection endpoint listing the entities.
+	URL string
+	// Param is the query parameter that scopes requests to an entity.
+	Param string
+	// CacheKey is the key of the resolved entity in the connection cache.
+	CacheKey string
+}
+
+var (
+	organizationTaxonomy = taxonomy{
+		Attribute: "organisation",
+		Entity:    "organization",
+		URL:       "/api/organizations",
+		Param:     "organization_id",
+		CacheKey:  "satellite_organization",
+	}
+	locationTaxonomy = taxonomy{
+		Attribute: "location",
+		Entity:    "location",
+		URL:       "/api/locations",
+		Param:     "location_id",
+		CacheKey:  "satellite_location",
+	}
+)
+
+type apiTaxonomy struct {
+	ID          int    `json:"id,omitempty" yaml:"id,omitempty"`
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Title       string `json:"title,omitempty" yaml:"title,omitempty"`
+	Label       string `json:"label,omitempty" yaml:"label,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// getTaxonomy returns the organization or location the connection is scoped
+// to, resolving it through the API the first time and then caching it in the
+// connection cache.
+func getTaxonomy(ctx context.Context, d *plugin.QueryData, client *resty.Client, kind taxonomy, value string) (apiTaxonomy, error) {
+	if cachedData, ok := d.ConnectionManager.Cache.Get(kind.CacheKey); ok {
+		plugin.Logger(ctx).Debug("returning taxonomy from cache", "entity", kind.Entity)
+		return cachedData.(apiTaxonomy), nil
+	}
+
+	entity, err := resolveTaxonomy(ctx, client, GetPerPage(d.Connection), kind, value)
+	if err != nil {
+		return entity, err
+	}
+	plugin.Logger(ctx).Debug("saving taxonomy to cache", "entity", kind.Entity, "value", value, "id", entity.ID)
+	d.ConnectionManager.Cache.Set(kind.CacheKey, entity)
+	return entity, nil
+}
+
+// resolveTaxonomy looks up the organization or location identified by value,
+// which can be its numeric ID, its name, its title or its label.
+func resolveTaxonomy(ctx context.Context, client *resty.Client, perPage int, kind taxonomy, value string) (apiTaxonomy, error) {
+	entities, err := listTaxonomies(ctx, client, perPage, kind)
+	if err != nil {
+		return apiTaxonomy{}, fmt.Errorf("error resolving %s %q: %w", kind.Attribute, value, err)
+	}
+	if entity, ok := matchTaxonomy(entities, value); ok {
+		return entity, nil
+	}
+	return apiTaxonomy{}, unknownTaxonomy(kind, value, entities)
+}
+
+// listTaxonomies returns all the organizations or locations visible to the
+// API user.
+func listTaxonomies(ctx context.Context, client *resty.Client, perPage int, kind taxonomy) ([]apiTaxonomy, error) {
+	entities := []apiTaxonomy{}
+	err := paginate(ctx, client, perPage, kind.URL, nil, func(entity apiTaxonomy) bool {
+		entities = append(entities, entity)
+		return true
+	})
+	return entities, err
+}
+
+// matchTaxonomy finds the entity identified by value, which can be its
+// numeric ID, its name, its title or its label; exact matches are preferred
+// over case-insensitive ones.
+func matchTaxonomy(entities []apiTaxonomy, value string) (apiTaxonomy, bool) {
+	if id, err := strconv.Atoi(value); err == nil {
+		for _, entity := range entities {
+			if entity.ID == id {
+				return entity, true
+			}
+		}
+	}
+	for _, equal := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		for _, entity := range entities {
+			if equal(entity.Name, value) || equal(entity.Title, value) || equal(entity.Label, value) {
+				return entity, true
+			}
+		}
+	}
+	return apiTaxonomy{}, false
+}
+
+// unknownTaxonomy returns an error listing the valid choices for value.
+func unknownTaxonomy(kind taxonomy, value string, entities []apiTaxonomy) error {
+	if len(entities) == 0 {
+		return fmt.Errorf("unknown %s %q: the user has no access to any %s", kind.Attribute, value, kind.Entity)
+	}
+	sorted := append([]apiTaxonomy{}, entities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	choices := make([]string, 0, len(sorted))
+	for _, entity := range sorted {
+		choices = append(choices, fmt.Sprintf("%d (name %q, label %q)", entity.ID, entity.Name, entity.Label))
+	}
+	return fmt.Errorf("unknown %s %q: it must be the ID, name or label of one of %s", kind.Attribute, value, strings.Join(choices, ", "))
+}
@@ -0,0 +1,59 @@
+package satellite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+)
+
+func TestResolveTaxonomy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/organizations" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"total":3,"subtotal":3,"page":"1","per_page":100,"results":[
+			{"id":3,"name":"Default Organization","title":"Default Organization","label":"Default_Organization"},
+			{"id":1,"name":"ACME Corp","title":"ACME Corp","label":"acme_corp"},
+			{"id":12,"name":"acme corp","title":"acme corp","label":"acme_corp_lowercase"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{value: "1", expected: 1},
+		{value: "ACME Corp", expected: 1},
+		{value: "acme corp", expected: 12},
+		{value: "acme_corp", expected: 1},
+		{value: "DEFAULT_ORGANIZATION", expected: 3},
+		{value: "12", expected: 12},
+	}
+	for _, test := range tests {
+		entity, err := resolveTaxonomy(testContext(), client, 0, organizationTaxonomy, test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entity.ID != test.expected {
+			t.Fatalf("error: %q: expected ID %d, got %d", test.value, test.expected, entity.ID)
+		}
+	}
+
+	_, err := resolveTaxonomy(testContext(), client, 0, organizationTaxonomy, "Initech")
+	t.Logf("unknown: %v", err)
+	if err == nil || !strings.Contains(err.Error(), `1 (name "ACME Corp", label "acme_corp"), 3 (name "Default Organization"`) {
+		t.Fatalf("error: expected the valid choices, got %v", err)
+	}
+
+	if _, err := resolveTaxonomy(testContext(), client, 0, locationTaxonomy, "Rome"); err == nil {
+		t.Fatal("error: expected request failure")
+	}
+}
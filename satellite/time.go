@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
@@ -29,18 +31,51 @@ func (t Time) MarshalJSON() ([]byte, error) {
 	return b, nil
 }
 
-// UnmarshalJSON implements the json.Unmarshaler interface.
+// UnmarshalJSON implements the json.Unmarshaler interface; see parseTime for
+// the supported formats.
 func (t *Time) UnmarshalJSON(data []byte) error {
 	// Ignore null, like in the main JSON package.
 	if string(data) == "null" {
 		return nil
 	}
-	// Fractional seconds are handled implicitly by Parse.
-	s, err := time.Parse(`"`+layout+`"`, string(data))
+	value := string(data)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	s, err := parseTime(value)
 	*t = Time(s)
 	return err
 }
 
+// timeLayouts are the layouts of the times that Satellite and Katello emit,
+// depending on the endpoint and on the version.
+var timeLayouts = []string{
+	layout,                      // 2020-06-10 10:03:19 UTC
+	"2006-01-02 15:04:05 -0700", // 2020-06-10 10:03:19 +0200
+	time.RFC3339Nano,            // 2020-06-10T10:03:19.123Z, 2020-06-10T10:03:19+02:00
+	"2006-01-02T15:04:05",       // 2020-06-10T10:03:19, in UTC
+	"2006-01-02 15:04:05",       // 2020-06-10 10:03:19, in UTC
+	"2006-01-02",                // 2020-06-10, at midnight UTC
+}
+
+// parseTime parses a time in any of the timeLayouts, or as seconds since the
+// epoch; an empty string is the zero time.
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %q", value)
+}
+
 func (t *Time) String() string {
 	if t == nil || (time.Time(*t)).UnixNano() == 0 {
 		return ""
@@ -55,26 +90,51 @@ func (t *Time) IsZero() bool {
 	return time.Time(*t).IsZero()
 }
 
-func ToTime(ctx context.Context, d *transform.TransformData) (any, error) {
-	var err error
-	switch t := d.Value.(type) {
+// ToTimestamp converts the value into a time.Time, as expected by TIMESTAMP
+// columns; zero times are turned into null.
+func ToTimestamp(ctx context.Context, d *transform.TransformData) (any, error) {
+	t, err := toTime(d.Value)
+	if err != nil || t.IsZero() {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ToAgeSeconds returns the number of seconds elapsed since the time in the
+// value; zero times are turned into null.
+func ToAgeSeconds(ctx context.Context, d *transform.TransformData) (any, error) {
+	t, err := toTime(d.Value)
+	if err != nil || t.IsZero() {
+		return nil, err
+	}
+	return int64(now().Sub(t) / time.Second), nil
+}
+
+// now is the current time, replaced in tests.
+var now = time.Now
+
+// toTime converts the supported time types, and strings in any of the
+// supported formats, into a time.Time.
+func toTime(value any) (time.Time, error) {
+	switch t := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case string:
+		return parseTime(t)
 	case *Time:
-		if t == nil || t.IsZero() {
-			return nil, nil
+		if t == nil {
+			return time.Time{}, nil
 		}
-		return t.String(), nil
+		return time.Time(*t), nil
 	case Time:
-		if t.IsZero() {
-			return nil, nil
-		}
-		return t.String(), nil
+		return time.Time(t), nil
 	case time.Time:
-		if t.IsZero() {
-			return nil, nil
+		return t, nil
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, nil
 		}
-		return t.String(), nil
-	default:
-		err = fmt.Errorf("invalid type: %T", d.Value)
+		return *t, nil
 	}
-	return nil, err
+	return time.Time{}, fmt.Errorf("invalid type: %T", value)
 }
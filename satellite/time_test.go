@@ -1,9 +1,13 @@
 package satellite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
 )
 
 func TestTime(t *testing.T) {
@@ -31,3 +35,78 @@ func TestTime(t *testing.T) {
 		}
 	}
 }
+
+func TestToAgeSeconds(t *testing.T) {
+	defer func(saved func() time.Time) { now = saved }(now)
+	now = func() time.Time { return time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC) }
+
+	checkin := Time(time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC))
+	tests := []struct {
+		value    any
+		expected any
+	}{
+		{value: &checkin, expected: int64(3600)},
+		{value: (*Time)(nil), expected: nil},
+		{value: nil, expected: nil},
+	}
+	for _, test := range tests {
+		actual, err := ToAgeSeconds(context.Background(), &transform.TransformData{Value: test.value})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Fatalf("error: expected %v, got %v", test.expected, actual)
+		}
+	}
+
+	timestamp, err := ToTimestamp(context.Background(), &transform.TransformData{Value: &checkin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !timestamp.(time.Time).Equal(time.Time(checkin)) {
+		t.Fatalf("error: unexpected timestamp %v", timestamp)
+	}
+	if _, err := ToTimestamp(context.Background(), &transform.TransformData{Value: "yesterday"}); err == nil {
+		t.Fatal("error: expected invalid type")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2020, 6, 10, 10, 3, 19, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "2020-06-10 10:03:19 UTC", expected: expected},
+		{value: "2020-06-10 12:03:19 +0200", expected: expected},
+		{value: "2020-06-10T10:03:19Z", expected: expected},
+		{value: "2020-06-10T12:03:19+02:00", expected: expected},
+		{value: "2020-06-10T10:03:19.000Z", expected: expected},
+		{value: "2020-06-10T10:03:19", expected: expected},
+		{value: "2020-06-10 10:03:19", expected: expected},
+		{value: "2020-06-10", expected: time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{value: "1591783399", expected: expected},
+		{value: ""},
+	}
+	for _, test := range tests {
+		actual, err := parseTime(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !actual.Equal(test.expected) {
+			t.Fatalf("error: %q: expected %v, got %v", test.value, test.expected, actual)
+		}
+	}
+	if _, err := parseTime("yesterday"); err == nil {
+		t.Fatal("error: expected unsupported time format")
+	}
+
+	// epoch seconds may come as JSON numbers too
+	a := struct{ Time *Time }{}
+	if err := json.Unmarshal([]byte(`{"Time": 1591783399}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(*a.Time).Equal(expected) {
+		t.Fatalf("error: expected %v, got %v", expected, time.Time(*a.Time))
+	}
+}
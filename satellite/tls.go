@@ -0,0 +1,90 @@
+package satellite
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// getTLSConfig builds the TLS configuration of the Satellite client: the CA
+// bundles in ca_file and ca_pem (e.g. the Katello katello-server-ca.crt) are
+// trusted in addition to the system ones, client_cert_file and client_key_file
+// enable client certificate authentication and insecure_skip_verify disables
+// server certificate verification altogether. It returns nil if there is
+// nothing to configure.
+func getTLSConfig(config satelliteConfig) (*tls.Config, error) {
+	hasCA := (config.CAFile != nil && *config.CAFile != "") || (config.CAPEM != nil && *config.CAPEM != "")
+	hasCert := config.ClientCertFile != nil && *config.ClientCertFile != ""
+	hasKey := config.ClientKeyFile != nil && *config.ClientKeyFile != ""
+	insecure := config.InsecureSkipVerify != nil && *config.InsecureSkipVerify
+
+	if !hasCA && !hasCert && !hasKey && !insecure {
+		return nil, nil
+	}
+
+	result := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecure,
+	}
+
+	if hasCA {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if config.CAFile != nil && *config.CAFile != "" {
+			data, err := os.ReadFile(*config.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ca_file: error reading CA bundle %q: %w", *config.CAFile, err)
+			}
+			if !pool.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("invalid ca_file: no PEM certificates found in %q", *config.CAFile)
+			}
+		}
+		if config.CAPEM != nil && *config.CAPEM != "" {
+			if !pool.AppendCertsFromPEM([]byte(*config.CAPEM)) {
+				return nil, errors.New("invalid ca_pem: no PEM certificates found")
+			}
+		}
+		result.RootCAs = pool
+	}
+
+	if hasCert != hasKey {
+		return nil, errors.New("invalid client certificate: client_cert_file and client_key_file must be set together")
+	}
+	if hasCert {
+		certificate, err := tls.LoadX509KeyPair(*config.ClientCertFile, *config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: error loading %q and %q: %w", *config.ClientCertFile, *config.ClientKeyFile, err)
+		}
+		result.Certificates = []tls.Certificate{certificate}
+	}
+
+	return result, nil
+}
+
+// isCertificateError tells whether the error is caused by the failed
+// verification of the server certificate.
+func isCertificateError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+	)
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname)
+}
+
+// certificateError adds a hint on how to fix the configuration to errors
+// caused by the failed verification of the server certificate; other errors
+// are returned as they are.
+func certificateError(err error) error {
+	if !isCertificateError(err) {
+		return err
+	}
+	return fmt.Errorf("server certificate verification failed, set ca_file or ca_pem to the Satellite CA bundle (e.g. katello-server-ca.crt) or, for testing only, insecure_skip_verify = true: %w", err)
+}
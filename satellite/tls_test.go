@@ -0,0 +1,72 @@
+package satellite
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/go-resty/resty/v2"
+)
+
+func TestTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	caFile := filepath.Join(t.TempDir(), "katello-server-ca.crt")
+	if err := os.WriteFile(caFile, []byte(ca), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config satelliteConfig
+		valid  bool
+	}{
+		{name: "system CAs", config: satelliteConfig{}, valid: false},
+		{name: "ca_file", config: satelliteConfig{CAFile: &caFile}, valid: true},
+		{name: "ca_pem", config: satelliteConfig{CAPEM: &ca}, valid: true},
+		{name: "insecure_skip_verify", config: satelliteConfig{InsecureSkipVerify: utils.PointerTo(true)}, valid: true},
+	}
+
+	for _, test := range tests {
+		tlsConfig, err := getTLSConfig(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := resty.New().SetBaseURL(server.URL)
+		if tlsConfig != nil {
+			client.SetTLSClientConfig(tlsConfig)
+		}
+		_, err = client.R().Get("/api/status")
+		t.Logf("%s: %v", test.name, certificateError(err))
+		if (err == nil) != test.valid {
+			t.Fatalf("error: %s: expected success %t, got error %v", test.name, test.valid, err)
+		}
+		if err != nil && !isCertificateError(err) {
+			t.Fatalf("error: %s: expected a certificate error, got %v", test.name, err)
+		}
+	}
+}
+
+func TestTLSConfigInvalid(t *testing.T) {
+	for _, config := range []satelliteConfig{
+		{CAFile: utils.PointerTo(filepath.Join(t.TempDir(), "missing.crt"))},
+		{CAPEM: utils.PointerTo("not a certificate")},
+		{ClientCertFile: utils.PointerTo("client.crt")},
+		{ClientKeyFile: utils.PointerTo("client.key")},
+	} {
+		_, err := getTLSConfig(config)
+		t.Logf("error: %v", err)
+		if err == nil {
+			t.Fatal("error: expected an invalid configuration")
+		}
+	}
+}
@@ -3,6 +3,7 @@ package satellite
 import (
 	"context"
 	"errors"
+	"sort"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
@@ -19,3 +20,29 @@ func setLogLevel(ctx context.Context, d *plugin.QueryData) {
 		plugin.Logger(ctx).SetLevel(hclog.LevelFromString(level))
 	}
 }
+
+// sortedKeys returns the keys of a map in alphabetical order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isRequested tells whether the query selects or filters on any of the given
+// columns.
+func isRequested(d *plugin.QueryData, columns ...string) bool {
+	for _, column := range columns {
+		if _, ok := d.Quals[column]; ok {
+			return true
+		}
+		for _, selected := range d.QueryContext.Columns {
+			if selected == column {
+				return true
+			}
+		}
+	}
+	return false
+}
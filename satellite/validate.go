@@ -0,0 +1,202 @@
+package satellite
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/turbot/steampipe-plugin-sdk/v5/connection"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// ValidatedConfigKey is the key of the validated configuration of a
+// connection in the connection cache.
+const ValidatedConfigKey = "satellite_validated_config"
+
+// validatedConfig is the configuration of a connection after the fallbacks
+// chosen via credentials_source have been applied and all the settings have
+// been validated, along with the TLS configuration built to validate them.
+type validatedConfig struct {
+	Config satelliteConfig
+	TLS    *tls.Config
+}
+
+// getValidatedConfig returns the validated configuration of a connection
+// from the connection cache, validating it and caching it on first use, so
+// that connections are only validated once, whether when they are loaded or
+// when their client is created.
+func getValidatedConfig(ctx context.Context, cache *connection.ConnectionCache, connection *plugin.Connection) (*validatedConfig, error) {
+	if cached, ok := cache.Get(ctx, ValidatedConfigKey); ok {
+		return cached.(*validatedConfig), nil
+	}
+	validated, err := validateConnection(connection)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Set(ctx, ValidatedConfigKey, validated); err != nil {
+		plugin.Logger(ctx).Warn("error caching validated connection configuration", "error", err)
+	}
+	return validated, nil
+}
+
+// validateConnection checks the configuration of a connection, after the
+// fallbacks chosen via credentials_source have been applied.
+func validateConnection(connection *plugin.Connection) (*validatedConfig, error) {
+	name := ""
+	if connection != nil {
+		name = connection.Name
+	}
+	config, err := resolveConfig(GetConfig(connection))
+	if err != nil {
+		return nil, fmt.Errorf("connection %q: %w", name, err)
+	}
+	tlsConfig, err := validateConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("connection %q: %w", name, err)
+	}
+	return &validatedConfig{Config: config, TLS: tlsConfig}, nil
+}
+
+// validateConfig checks that all the connection settings are well-formed and
+// returns the TLS configuration built along the way; the returned error
+// reports all the offending attributes, each with a suggestion on how to fix
+// it.
+func validateConfig(config satelliteConfig) (*tls.Config, error) {
+	problems := []string{}
+
+	if config.EndpointURL != nil && config.Endpoint != nil && *config.EndpointURL != *config.Endpoint {
+		problems = append(problems, "both endpoint and endpoint_url are set: remove endpoint_url, which is a deprecated alias of endpoint")
+	}
+	if err := validateEndpoint(config.Endpoint); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if _, err := authenticationMethods(config); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for _, attribute := range []struct {
+		name  string
+		value *string
+	}{
+		{"username", config.Username},
+		{"token", config.Token},
+		{"oauth_consumer_key", config.OAuthConsumerKey},
+		{"oauth_consumer_secret", config.OAuthConsumerSecret},
+		{"oauth_user", config.OAuthUser},
+	} {
+		if attribute.value != nil && strings.IndexFunc(*attribute.value, unicode.IsSpace) >= 0 {
+			problems = append(problems, fmt.Sprintf("invalid %s: it must not contain whitespace, check for stray spaces or newlines when copying it", attribute.name))
+		}
+	}
+	for _, attribute := range []struct {
+		name  string
+		value *string
+	}{
+		{"username", config.Username},
+		{"password", config.Password},
+		{"token", config.Token},
+	} {
+		if attribute.value != nil && *attribute.value == "" {
+			problems = append(problems, fmt.Sprintf("invalid %s: it is set but empty, remove it or give it a value", attribute.name))
+		}
+	}
+
+	for _, attribute := range []struct {
+		name   string
+		value  *string
+		entity string
+	}{
+		{"organisation", config.Organisation, "organization"},
+		{"location", config.Location, "location"},
+	} {
+		if attribute.value != nil && strings.TrimSpace(*attribute.value) == "" {
+			problems = append(problems, fmt.Sprintf("invalid %s: it is set but empty, set it to the ID, name or label of the %s, as shown by 'hammer %s list'", attribute.name, attribute.entity, attribute.entity))
+		}
+	}
+
+	if config.Organisation != nil && len(config.Organizations) > 0 {
+		problems = append(problems, "both organisation and organizations are set: use organisation for a single organization, or organizations for several ones (\"*\" for all)")
+	}
+	for _, organization := range config.Organizations {
+		if strings.TrimSpace(organization) == "" {
+			problems = append(problems, "invalid organizations: it has an empty entry, each one must be the ID, name or label of an organization, or \"*\" for all")
+			break
+		}
+	}
+
+	if config.TraceLevel != nil && hclog.LevelFromString(*config.TraceLevel) == hclog.NoLevel {
+		problems = append(problems, fmt.Sprintf("invalid trace_level %q: it must be one of \"trace\", \"debug\", \"info\", \"warn\", \"error\" or \"off\"", *config.TraceLevel))
+	}
+	if config.PerPage != nil && *config.PerPage <= 0 {
+		problems = append(problems, fmt.Sprintf("invalid per_page %d: it must be a positive number of items, e.g. %d", *config.PerPage, DefaultPerPage))
+	}
+	if config.MaxConcurrency != nil && *config.MaxConcurrency <= 0 {
+		problems = append(problems, fmt.Sprintf("invalid max_concurrency %d: it must be a positive number of hosts, e.g. %d", *config.MaxConcurrency, DefaultMaxConcurrency))
+	}
+	if config.HostIndexTTL != nil {
+		if ttl, err := time.ParseDuration(*config.HostIndexTTL); err != nil || ttl <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid host_index_ttl %q: it must be a positive duration, e.g. %q", *config.HostIndexTTL, DefaultHostIndexTTL.String()))
+		}
+	}
+	if _, err := getRetryConfig(config); err != nil {
+		problems = append(problems, err.Error())
+	}
+	tlsConfig, err := getTLSConfig(config)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return tlsConfig, nil
+}
+
+// validateEndpoint checks that the endpoint is the base URL of the Satellite
+// server, without the /api path that the tables add themselves.
+func validateEndpoint(endpoint *string) error {
+	if endpoint == nil || *endpoint == "" {
+		return fmt.Errorf("missing endpoint: set it to the URL of the Satellite server, e.g. endpoint = \"https://satellite.example.com\"")
+	}
+	u, err := url.Parse(*endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint %q: %v; set it to the URL of the Satellite server, e.g. \"https://satellite.example.com\"", *endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("invalid endpoint %q: it must start with https://, e.g. \"https://satellite.example.com\"", *endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q: it has no host name, e.g. \"https://satellite.example.com\"", *endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid endpoint %q: it must not have a query or a fragment, set it to %q", *endpoint, u.Scheme+"://"+u.Host+u.Path)
+	}
+	path := strings.TrimRight(u.Path, "/")
+	for _, suffix := range []string{"/api", "/api/v2"} {
+		if strings.HasSuffix(path, suffix) {
+			u.Path = strings.TrimSuffix(path, suffix)
+			return fmt.Errorf("invalid endpoint %q: it ends with %s, which the tables add themselves; set it to %q", *endpoint, suffix, u.String())
+		}
+	}
+	return nil
+}
+
+// connectionConfigChanged clears the caches of a connection whose
+// configuration has changed, then validates the new configuration so that
+// errors are logged as soon as the .spc file is saved; the caches are cleared
+// first since the SDK ignores the returned error, and an invalid connection
+// must not keep serving from the client built for the old one.
+func connectionConfigChanged(ctx context.Context, p *plugin.Plugin, old *plugin.Connection, new *plugin.Connection) error {
+	p.ClearConnectionCache(ctx, new.Name)
+	p.ClearQueryCache(ctx, new.Name)
+	if _, err := validateConnection(new); err != nil {
+		plugin.Logger(ctx).Error("invalid connection configuration", "connection", new.Name, "error", err)
+		return err
+	}
+	return nil
+}
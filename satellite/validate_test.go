@@ -0,0 +1,152 @@
+package satellite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dihedron/steampipe-plugin-utils/utils"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func TestValidateConfig(t *testing.T) {
+	valid := func() satelliteConfig {
+		return satelliteConfig{
+			Endpoint:     utils.PointerTo("https://satellite.example.com"),
+			Username:     utils.PointerTo("admin"),
+			Password:     utils.PointerTo("secret"),
+			Organisation: utils.PointerTo("1"),
+			TraceLevel:   utils.PointerTo("TRACE"),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		update   func(*satelliteConfig)
+		expected string
+	}{
+		{
+			name:   "valid",
+			update: func(c *satelliteConfig) {},
+		},
+		{
+			name:     "missing endpoint",
+			update:   func(c *satelliteConfig) { c.Endpoint = nil },
+			expected: "missing endpoint",
+		},
+		{
+			name:     "endpoint with /api",
+			update:   func(c *satelliteConfig) { c.Endpoint = utils.PointerTo("https://satellite.example.com/api/") },
+			expected: `set it to "https://satellite.example.com"`,
+		},
+		{
+			name:     "endpoint with /api/v2",
+			update:   func(c *satelliteConfig) { c.Endpoint = utils.PointerTo("https://satellite.example.com:8443/api/v2") },
+			expected: `set it to "https://satellite.example.com:8443"`,
+		},
+		{
+			name:     "endpoint without scheme",
+			update:   func(c *satelliteConfig) { c.Endpoint = utils.PointerTo("satellite.example.com") },
+			expected: "it must start with https://",
+		},
+		{
+			name:     "endpoint and endpoint_url",
+			update:   func(c *satelliteConfig) { c.EndpointURL = utils.PointerTo("https://other.example.com") },
+			expected: "remove endpoint_url",
+		},
+		{
+			name:     "token with newline",
+			update:   func(c *satelliteConfig) { c.Token = utils.PointerTo("token\n") },
+			expected: "invalid token: it must not contain whitespace",
+		},
+		{
+			name:     "empty password",
+			update:   func(c *satelliteConfig) { c.Password = utils.PointerTo("") },
+			expected: "invalid password: it is set but empty",
+		},
+		{
+			name:   "organisation name",
+			update: func(c *satelliteConfig) { c.Organisation = utils.PointerTo("ACME") },
+		},
+		{
+			name:     "empty organisation",
+			update:   func(c *satelliteConfig) { c.Organisation = utils.PointerTo(" ") },
+			expected: "'hammer organization list'",
+		},
+		{
+			name:     "trace level",
+			update:   func(c *satelliteConfig) { c.TraceLevel = utils.PointerTo("verbose") },
+			expected: `invalid trace_level "verbose"`,
+		},
+		{
+			name:     "per page",
+			update:   func(c *satelliteConfig) { c.PerPage = utils.PointerTo(0) },
+			expected: "invalid per_page 0",
+		},
+		{
+			name: "all problems reported",
+			update: func(c *satelliteConfig) {
+				c.Location = utils.PointerTo("")
+				c.MaxConcurrency = utils.PointerTo(-5)
+			},
+			expected: "'hammer location list'; invalid max_concurrency -5",
+		},
+	}
+
+	for _, test := range tests {
+		config := valid()
+		test.update(&config)
+		_, err := validateConfig(config)
+		t.Logf("%s: %v", test.name, err)
+		if test.expected == "" {
+			if err != nil {
+				t.Fatalf("error: %s: unexpected error %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Fatalf("error: %s: expected error containing %q, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestValidateOnLoad(t *testing.T) {
+	tableMapFunc := Plugin(testContext()).TableMapFunc
+
+	invalid := satelliteConfig{
+		Endpoint:          utils.PointerTo("https://satellite.example.com/api"),
+		Username:          utils.PointerTo("admin"),
+		Password:          utils.PointerTo("secret"),
+		CredentialsSource: utils.PointerTo(CredentialsSourceConfig),
+	}
+	valid := invalid
+	valid.Endpoint = utils.PointerTo("https://satellite.example.com")
+
+	// the SDK builds the table map for the first connection only, so an
+	// invalid first connection must not fail the ones that follow
+	invalidCache := newTestConnectionCache(t)
+	invalidConnection := &plugin.Connection{Name: "satellite_invalid", Config: invalid}
+	tables, err := tableMapFunc(testContext(), &plugin.TableMapData{
+		Connection:     invalidConnection,
+		ConectionCache: invalidCache,
+	})
+	if err != nil || tables["satellite_host"] == nil {
+		t.Fatalf("error: expected the tables despite the invalid connection, got %v", err)
+	}
+	if _, ok := invalidCache.Get(testContext(), ValidatedConfigKey); ok {
+		t.Fatal("error: invalid configuration cached")
+	}
+	_, err = getValidatedConfig(testContext(), invalidCache, invalidConnection)
+	if err == nil || !strings.Contains(err.Error(), `connection "satellite_invalid": invalid configuration: invalid endpoint`) {
+		t.Fatalf("error: expected an invalid endpoint error, got %v", err)
+	}
+
+	validCache := newTestConnectionCache(t)
+	validConnection := &plugin.Connection{Name: "satellite_valid", Config: valid}
+	if _, err := getValidatedConfig(testContext(), validCache, validConnection); err != nil {
+		t.Fatalf("error: unexpected error on the valid connection: %v", err)
+	}
+	cached, ok := validCache.Get(testContext(), ValidatedConfigKey)
+	if !ok || *cached.(*validatedConfig).Config.Endpoint != "https://satellite.example.com" {
+		t.Fatalf("error: expected the validated configuration to be cached, got %v", cached)
+	}
+}